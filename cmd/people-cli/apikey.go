@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"people-service/config"
+	"people-service/internal/lib/auth"
+)
+
+const apiKeyUsage = `usage: people-cli apikey <command>
+
+commands:
+  issue -name <name> [-role reader|editor|admin]
+                       issue a new API key and print it once
+  list                 list issued API keys
+  revoke <id>          revoke an API key
+`
+
+func runAPIKey(log *slog.Logger, args []string) error {
+	if len(args) < 1 {
+		fmt.Fprint(os.Stderr, apiKeyUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "issue":
+		return runAPIKeyIssue(log, args[1:])
+	case "list":
+		return runAPIKeyList(log)
+	case "revoke":
+		return runAPIKeyRevoke(log, args[1:])
+	default:
+		fmt.Fprint(os.Stderr, apiKeyUsage)
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+func runAPIKeyIssue(log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("apikey issue", flag.ExitOnError)
+	nameFlag := fs.String("name", "", "who or what the key is for")
+	roleFlag := fs.String("role", string(auth.RoleReader), "role granted by the key: reader, editor or admin")
+	fs.Parse(args)
+
+	if *nameFlag == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	role, err := auth.ParseRole(*roleFlag)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(log, config.MustLoad())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+	apiKey, err := store.CreateAPIKey(context.Background(), *nameFlag, string(role), auth.HashAPIKey(key))
+	if err != nil {
+		return err
+	}
+
+	log.Info("api key issued", slog.Int("id", apiKey.Id), slog.String("name", apiKey.Name), slog.String("role", apiKey.Role))
+	fmt.Println(key)
+
+	return nil
+}
+
+func runAPIKeyList(log *slog.Logger) error {
+	store, err := openStorage(log, config.MustLoad())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	keys, err := store.ListAPIKeys(context.Background())
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tROLE\tCREATED\tREVOKED")
+	for _, k := range keys {
+		revoked := "-"
+		if k.RevokedAt != nil {
+			revoked = k.RevokedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", k.Id, k.Name, k.Role, k.CreatedAt.Format(time.RFC3339), revoked)
+	}
+
+	return tw.Flush()
+}
+
+func runAPIKeyRevoke(log *slog.Logger, args []string) error {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, apiKeyUsage)
+		os.Exit(2)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid api key id %q", args[0])
+	}
+
+	store, err := openStorage(log, config.MustLoad())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.RevokeAPIKey(context.Background(), id); err != nil {
+		return err
+	}
+
+	log.Info("api key revoked", slog.Int("id", id))
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"people-service/config"
+	"people-service/internal/data-prep/age"
+	"people-service/internal/data-prep/enrich"
+	"people-service/internal/data-prep/gender"
+	"people-service/internal/data-prep/nationality"
+	"people-service/internal/lib/csvimport"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/storage"
+	"people-service/internal/storage/pg"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: people-cli <command> [flags]
+
+commands:
+  import   import people from a CSV file
+  apikey   issue, list and revoke API keys
+`
+
+// IS: Same as in people-service, env variables come from .env for the study case.
+func init() {
+	if err := godotenv.Load(); err != nil {
+		fmt.Println("No .env file found")
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	log := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	var err error
+	switch os.Args[1] {
+	case "import":
+		err = runImport(log, os.Args[2:])
+	case "apikey":
+		err = runAPIKey(log, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Error("command failed", sl.Err(err))
+		os.Exit(1)
+	}
+}
+
+func runImport(log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	mappingFlag := fs.String("map", "", `column mapping, e.g. "First Name:name,Last Name:surname"`)
+	enrichFlag := fs.Bool("enrich", false, "look missing age, gender and nationality up upstream")
+	reportFlag := fs.String("report", "", "file to write rejected rows to (default stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: people-cli import [flags] <file.csv>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var mapping csvimport.Mapping
+	if *mappingFlag != "" {
+		var err error
+		if mapping, err = csvimport.ParseMapping(*mappingFlag); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	cfg := config.MustLoad()
+
+	store, err := openStorage(log, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	enricher := enrich.New(log,
+		age.New(log, cfg.AgeServiceUrl, cfg.EnrichCacheTTL),
+		gender.New(log, cfg.GenderServiceUrl, cfg.EnrichCacheTTL),
+		nationality.New(log, cfg.NationalityServiceUrl, cfg.EnrichCacheTTL),
+	)
+
+	summary, err := csvimport.New(store, enricher).Import(context.Background(), file, mapping, *enrichFlag)
+	if err != nil {
+		return err
+	}
+
+	log.Info("people imported",
+		slog.Int("total", summary.Total),
+		slog.Int("imported", len(summary.Ids)),
+		slog.Int("rejected", len(summary.Rejected)),
+	)
+
+	if len(summary.Rejected) == 0 {
+		return nil
+	}
+
+	report := os.Stdout
+	if *reportFlag != "" {
+		if report, err = os.Create(*reportFlag); err != nil {
+			return err
+		}
+		defer report.Close()
+	}
+
+	return csvimport.WriteReport(report, summary)
+}
+
+func openStorage(log *slog.Logger, cfg *config.Config) (*pg.Storage, error) {
+	return pg.New(log, storage.PostgresConfig{
+		Host:     cfg.Storage.Host,
+		Port:     cfg.Storage.Port,
+		DBName:   cfg.Storage.DBName,
+		User:     cfg.Storage.User,
+		Password: cfg.Storage.Password,
+
+		ConnectTimeout: cfg.Storage.ConnectTimeout,
+
+		IdentityFields: cfg.Storage.IdentityFields,
+	})
+}
@@ -1,153 +1,449 @@
-package main
-
-import (
-	"context"
-	"fmt"
-	"log/slog"
-	"net/http"
-	"os"
-	"os/signal"
-	"people-service/config"
-	"people-service/internal/data-prep/age"
-	"people-service/internal/data-prep/gender"
-	"people-service/internal/data-prep/nationality"
-	"people-service/internal/http-server/handlers/person/delete"
-	"people-service/internal/http-server/handlers/person/get"
-	"people-service/internal/http-server/handlers/person/save"
-	"people-service/internal/http-server/handlers/person/update"
-	mwLogger "people-service/internal/http-server/middleware/logger"
-	"people-service/internal/lib/routing"
-	"people-service/internal/storage"
-	"people-service/internal/storage/pg"
-	"syscall"
-	"time"
-
-	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
-	"github.com/joho/godotenv"
-)
-
-const (
-	envLocal = "local"
-	envDev   = "dev"
-	envProd  = "prod"
-)
-
-// // IS: Use for easy init env variables. Not for production use. Only for study case.
-func init() {
-	// loads values from .env into the system
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found")
-	}
-}
-
-func main() {
-
-	cfg := config.MustLoad()
-
-	pgConfig := storage.PostgresConfig{
-		Host:     cfg.Storage.Host,
-		Port:     cfg.Storage.Port,
-		DBName:   cfg.Storage.DBName,
-		User:     cfg.Storage.User,
-		Password: cfg.Storage.Password,
-	}
-
-	log := setupLogger(cfg.Env)
-
-	log.Debug("init database")
-	storage, err := pg.New(log, pgConfig)
-	if err != nil {
-		log.Error("failed to init storage", err)
-		os.Exit(1)
-	}
-	log.Debug("db initialized")
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.CtxTimeout)*time.Second)
-	defer cancel()
-
-	log.Debug("initializing data preparation services")
-	ageService := age.New(log, cfg.AgeServiceUrl) // mock: "http://localhost:8098/age"
-	log.Debug("age service initialized")
-	genderService := gender.New(log, cfg.GenderServiceUrl) // mock: "http://localhost:8098/gender")
-	log.Debug("gender service initialized")
-	nationalityService := nationality.New(log, cfg.NationalityServiceUrl) // mock: "http://localhost:8098/nat"
-	log.Debug("nationality service initialized")
-
-	router := chi.NewRouter()
-	router.Use(middleware.RequestID)
-	router.Use(middleware.Logger)
-	router.Use(mwLogger.New(log))
-	router.Use(middleware.Recoverer)
-	router.Use(middleware.URLFormat)
-
-	router.Post("/person", save.New(log, storage, ageService, genderService, nationalityService))
-	router.Get("/person", get.New(log, storage))
-
-	router.Route(fmt.Sprintf("/person/{%s}", routing.PersonIdParam), func(r chi.Router) {
-		r.Delete("/", delete.New(log, storage))
-		r.Put("/", update.New(log, storage))
-	})
-
-	log.Info("starting server", slog.String("address", cfg.HTTPServer.Address))
-
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-
-	// IS: if have no access to real services, simple mock for them.
-	// TODO: better use testing and mocking frameworks
-	//mock.MockServices(cfg)
-
-	srv := &http.Server{
-		Addr:         cfg.HTTPServer.Address,
-		Handler:      router,
-		ReadTimeout:  cfg.HTTPServer.Timeout,
-		WriteTimeout: cfg.HTTPServer.Timeout,
-		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
-	}
-
-	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Error("failed to start server")
-		}
-	}()
-
-	log.Info("server started")
-
-	<-done
-	log.Info("stopping server")
-
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Error("failed to stop server", err)
-		return
-	}
-
-	storage.Close()
-
-	log.Info("server stopped")
-}
-
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
-
-	switch env {
-	case envLocal:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
-	default:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
-	}
-
-	return log
-}
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"people-service/config"
+	"people-service/internal/data-prep/age"
+	"people-service/internal/data-prep/enrich"
+	"people-service/internal/data-prep/gender"
+	"people-service/internal/data-prep/nationality"
+	"people-service/internal/http-server/handlers/health/live"
+	"people-service/internal/http-server/handlers/health/ready"
+	"people-service/internal/http-server/handlers/person/batch"
+	"people-service/internal/http-server/handlers/person/bulkdelete"
+	"people-service/internal/http-server/handlers/person/bulkupdate"
+	"people-service/internal/http-server/handlers/person/delete"
+	"people-service/internal/http-server/handlers/person/duplicates"
+	"people-service/internal/http-server/handlers/person/export"
+	"people-service/internal/http-server/handlers/person/get"
+	"people-service/internal/http-server/handlers/person/getbyid"
+	"people-service/internal/http-server/handlers/person/importcsv"
+	"people-service/internal/http-server/handlers/person/importreport"
+	"people-service/internal/http-server/handlers/person/merge"
+	"people-service/internal/http-server/handlers/person/save"
+	"people-service/internal/http-server/handlers/person/search"
+	"people-service/internal/http-server/handlers/person/stats"
+	"people-service/internal/http-server/handlers/person/update"
+	"people-service/internal/http-server/middleware/alias"
+	"people-service/internal/http-server/middleware/authn"
+	"people-service/internal/http-server/middleware/authz"
+	"people-service/internal/http-server/middleware/idempotency"
+	mwLogger "people-service/internal/http-server/middleware/logger"
+	mwMetrics "people-service/internal/http-server/middleware/metrics"
+	"people-service/internal/http-server/middleware/ratelimit"
+	mwTracing "people-service/internal/http-server/middleware/tracing"
+	"people-service/internal/http-server/middleware/validate"
+	"people-service/internal/http-server/middleware/versioning"
+	"people-service/internal/http-server/openapi"
+	"people-service/internal/lib/api/content"
+	"people-service/internal/lib/api/version"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/confirm"
+	"people-service/internal/lib/csvimport"
+	"people-service/internal/lib/health"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/metrics"
+	"people-service/internal/lib/rbac"
+	"people-service/internal/lib/routing"
+	"people-service/internal/lib/tracing"
+	"people-service/internal/storage"
+	"people-service/internal/storage/pg"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/joho/godotenv"
+)
+
+const serviceName = "people-service"
+
+const (
+	envLocal = "local"
+	envDev   = "dev"
+	envProd  = "prod"
+)
+
+// Kinds of /person routes with their own rate limits.
+const (
+	limitRead   = "read"
+	limitWrite  = "write"
+	limitEnrich = "enrich"
+)
+
+// // IS: Use for easy init env variables. Not for production use. Only for study case.
+func init() {
+	// loads values from .env into the system
+	if err := godotenv.Load(); err != nil {
+		fmt.Println("No .env file found")
+	}
+}
+
+func main() {
+
+	cfg := config.MustLoad()
+
+	pgConfig := storage.PostgresConfig{
+		Host:     cfg.Storage.Host,
+		Port:     cfg.Storage.Port,
+		DBName:   cfg.Storage.DBName,
+		User:     cfg.Storage.User,
+		Password: cfg.Storage.Password,
+
+		ConnectTimeout: cfg.Storage.ConnectTimeout,
+
+		IdentityFields: cfg.Storage.IdentityFields,
+	}
+
+	log := setupLogger(cfg.Env)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: serviceName,
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Error("failed to init tracing", sl.Err(err))
+		os.Exit(1)
+	}
+
+	log.Debug("init database")
+	storage, err := pg.New(log, pgConfig)
+	if err != nil {
+		log.Error("failed to init storage", sl.Err(err))
+		os.Exit(1)
+	}
+	log.Debug("db initialized")
+
+	metrics.ObserveDBPool(storage.Stats)
+
+	if err := storage.SyncIdentityKeys(context.Background()); err != nil {
+		log.Error("failed to sync person identity keys", sl.Err(err))
+		os.Exit(1)
+	}
+	if err := storage.BackfillSearchKeys(context.Background()); err != nil {
+		log.Error("failed to backfill person search keys", sl.Err(err))
+		os.Exit(1)
+	}
+	if err := storage.BackfillLatinNames(context.Background()); err != nil {
+		log.Error("failed to backfill latin names", sl.Err(err))
+		os.Exit(1)
+	}
+
+	log.Debug("initializing data preparation services")
+	ageService := age.New(log, cfg.AgeServiceUrl, cfg.EnrichCacheTTL) // mock: "http://localhost:8098/age"
+	log.Debug("age service initialized")
+	genderService := gender.New(log, cfg.GenderServiceUrl, cfg.EnrichCacheTTL) // mock: "http://localhost:8098/gender")
+	log.Debug("gender service initialized")
+	nationalityService := nationality.New(log, cfg.NationalityServiceUrl, cfg.EnrichCacheTTL) // mock: "http://localhost:8098/nat"
+	log.Debug("nationality service initialized")
+	enricher := enrich.New(log, ageService, genderService, nationalityService)
+
+	confirmer, err := confirm.New(cfg.ConfirmSecret, cfg.ConfirmTTL)
+	if err != nil {
+		log.Error("failed to init confirmer", sl.Err(err))
+		os.Exit(1)
+	}
+
+	importer := csvimport.New(storage, enricher)
+	importReports := csvimport.NewReports(time.Hour)
+
+	tokenVerifier, err := setupTokenVerifier(cfg.Auth.JWT)
+	if err != nil {
+		log.Error("failed to init jwt verifier", sl.Err(err))
+		os.Exit(1)
+	}
+
+	checker := setupReadiness(cfg, storage)
+
+	apiSpec := openapi.New()
+	router, err := setupRouter(log, cfg, apiSpec, services{
+		storage:            storage,
+		ageService:         ageService,
+		genderService:      genderService,
+		nationalityService: nationalityService,
+		enricher:           enricher,
+		confirmer:          confirmer,
+		importer:           importer,
+		importReports:      importReports,
+		tokenVerifier:      tokenVerifier,
+		checker:            checker,
+	})
+	if err != nil {
+		log.Error("failed to init router", sl.Err(err))
+		os.Exit(1)
+	}
+
+	if err := openapi.Verify(router, apiSpec); err != nil {
+		log.Error("API specification does not match routes", sl.Err(err))
+		os.Exit(1)
+	}
+
+	log.Info("starting server", slog.String("address", cfg.HTTPServer.Address))
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	// IS: if have no access to real services, simple mock for them.
+	// TODO: better use testing and mocking frameworks
+	//mock.MockServices(cfg)
+
+	srv := &http.Server{
+		Addr:         cfg.HTTPServer.Address,
+		Handler:      router,
+		ReadTimeout:  cfg.HTTPServer.Timeout,
+		WriteTimeout: cfg.HTTPServer.Timeout,
+		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("failed to start server")
+		}
+	}()
+
+	log.Info("server started")
+
+	<-done
+	log.Info("stopping server")
+
+	// Report not ready first and keep serving for a while, so that requests
+	// routed before the orchestrator notices are not refused.
+	checker.Shutdown()
+	time.Sleep(cfg.Health.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.CtxTimeout)*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error("failed to stop server", sl.Err(err))
+		return
+	}
+
+	storage.Close()
+
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error("failed to flush traces", sl.Err(err))
+	}
+
+	log.Info("server stopped")
+}
+
+// services are the dependencies the routes are served with.
+type services struct {
+	storage            *pg.Storage
+	ageService         *age.AgeService
+	genderService      *gender.GenderService
+	nationalityService *nationality.NationalityService
+	enricher           *enrich.Enricher
+	confirmer          *confirm.Confirmer
+	importer           *csvimport.Importer
+	importReports      *csvimport.Reports
+	tokenVerifier      authn.TokenVerifier
+	checker            *health.Checker
+}
+
+// setupRouter mounts every route of the service.
+func setupRouter(log *slog.Logger, cfg *config.Config, apiSpec *openapi.Document, svc services) (*chi.Mux, error) {
+	batchConfig := batch.Config{
+		Mode:     cfg.Batch.Mode,
+		MaxItems: cfg.Batch.MaxItems,
+	}
+
+	// The largest JSON body is a batch; NDJSON batches and CSV uploads are
+	// streamed by their handlers instead.
+	validation, err := validate.New(log, apiSpec, validate.Options{
+		Responses:    cfg.ValidateResponses,
+		MaxBodyBytes: batch.MaxBodySize(batchConfig),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(mwTracing.New)
+	router.Use(middleware.Logger)
+	router.Use(mwLogger.New(log))
+	router.Use(mwMetrics.New)
+	router.Use(middleware.Recoverer)
+	router.Use(middleware.URLFormat)
+
+	idempotent := idempotency.New(log, svc.storage, idempotency.Options{
+		TTL:   cfg.IdempotencyTTL,
+		Lease: cfg.IdempotencyLease,
+	})
+
+	rateLimit := ratelimit.Select(rateLimitClass, map[string]func(http.Handler) http.Handler{
+		limitRead:   ratelimit.New(log, limitRead, ratelimit.Limit(cfg.RateLimits.Read)),
+		limitWrite:  ratelimit.New(log, limitWrite, ratelimit.Limit(cfg.RateLimits.Write)),
+		limitEnrich: ratelimit.New(log, limitEnrich, ratelimit.Limit(cfg.RateLimits.Enrich)),
+	})
+
+	reader := authz.Require(log, auth.RoleReader)
+	editor := authz.Require(log, auth.RoleEditor)
+	admin := authz.Require(log, auth.RoleAdmin)
+	// Statistics are made of attribute values readers see redacted.
+	seeEnriched := authz.Require(log, rbac.SeeEnriched)
+
+	personRoutes := func(r chi.Router) {
+		r.With(editor, idempotent).Post("/", save.New(log, svc.storage, svc.ageService, svc.genderService, svc.nationalityService))
+		r.With(editor, idempotent).Post("/batch", batch.New(log, svc.storage, svc.enricher, batchConfig))
+		r.With(reader, content.Negotiate).Get("/", get.New(log, svc.storage, get.PageSize{
+			Default: cfg.Pagination.DefaultSize,
+			Max:     cfg.Pagination.MaxSize,
+		}))
+		r.With(admin).Patch("/", bulkupdate.New(log, svc.storage, svc.confirmer))
+		r.With(admin).Delete("/", bulkdelete.New(log, svc.storage, svc.confirmer))
+		r.With(admin).Post("/import", importcsv.New(log, svc.importer, svc.importReports))
+		r.With(admin).Get(fmt.Sprintf("/import/reports/{%s}", routing.ReportIdParam), importreport.New(log, svc.importReports))
+		r.With(admin).Get("/export", export.New(log, svc.storage, cfg.HTTPServer.Timeout))
+		r.With(reader, content.Negotiate).Get("/search", search.New(log, svc.storage))
+		r.With(seeEnriched).Get("/stats", stats.New(log, svc.storage))
+		r.With(editor, idempotent).Post("/merge", merge.New(log, svc.storage))
+
+		r.Route(fmt.Sprintf("/{%s}", routing.PersonIdParam), func(r chi.Router) {
+			merged := alias.New(log, svc.storage)
+
+			r.With(reader, merged, content.Negotiate).Get("/", getbyid.New(log, svc.storage))
+			r.With(editor, merged).Delete("/", delete.New(log, svc.storage))
+			r.With(editor, merged).Put("/", update.New(log, svc.storage))
+			r.With(reader, merged, content.Negotiate).Get("/duplicates", duplicates.New(log, svc.storage))
+		})
+	}
+
+	// The same routes are served under every version prefix and, with the
+	// version taken from the Accept header, without one.
+	router.Group(func(r chi.Router) {
+		r.Use(versioning.New(log, versioning.Options{Sunset: cfg.V1Sunset}))
+		r.Use(authn.New(log, svc.storage, svc.tokenVerifier, authn.Options{
+			Required:      cfg.Auth.Required,
+			AnonymousRole: auth.Role(cfg.Auth.AnonymousRole),
+		}))
+		r.Use(rateLimit)
+		r.Use(validation)
+
+		for _, v := range version.Supported {
+			r.Route(version.Prefix(v)+"/person", personRoutes)
+		}
+		r.Route("/person", personRoutes)
+	})
+
+	// URLFormat routes /openapi.json here with the extension stripped.
+	router.Get("/openapi", openapi.Handler(apiSpec))
+	router.Get("/docs", openapi.Docs(apiSpec, "/openapi.json"))
+
+	router.Get("/healthz", live.New())
+	router.Get("/readyz", ready.New(log, svc.checker))
+	router.Handle("/metrics", metrics.Handler())
+
+	return router, nil
+}
+
+// rateLimitClass picks the rate limit of a request to a /person route. The
+// limits apply before requests are routed, so it goes by the method and the
+// path without its version prefix.
+func rateLimitClass(r *http.Request) string {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return limitRead
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if v := version.FromPath(path); v != 0 {
+		path = strings.TrimPrefix(path, version.Prefix(v))
+	}
+	if r.Method == http.MethodPost {
+		switch path {
+		case "/person", "/person/batch", "/person/import":
+			return limitEnrich
+		}
+	}
+
+	return limitWrite
+}
+
+// setupReadiness checks the database and its schema and, if configured, that
+// the data-prep services answer.
+func setupReadiness(cfg *config.Config, storage *pg.Storage) *health.Checker {
+	checks := []health.Check{
+		{Name: "database", Check: storage.Ping},
+		{Name: "schema", Check: storage.CheckSchema},
+	}
+
+	if cfg.Health.CheckUpstreams {
+		client := &http.Client{}
+		upstreams := []struct{ name, url string }{
+			{"age_service", cfg.AgeServiceUrl},
+			{"gender_service", cfg.GenderServiceUrl},
+			{"nationality_service", cfg.NationalityServiceUrl},
+		}
+		for _, u := range upstreams {
+			checks = append(checks, health.Check{
+				Name:  u.name,
+				Check: health.Cached(health.Reachable(client, u.url), cfg.Health.UpstreamCacheTTL),
+			})
+		}
+	}
+
+	return health.NewChecker(checks...)
+}
+
+// setupTokenVerifier returns nil if no JWT keys are configured, so that only
+// API keys are accepted.
+func setupTokenVerifier(cfg config.JWT) (authn.TokenVerifier, error) {
+	jwtConfig := auth.JWTConfig{
+		HS256Secret: cfg.HS256Secret,
+		Issuer:      cfg.Issuer,
+		Audience:    cfg.Audience,
+	}
+	if cfg.RS256PublicKeyFile != "" {
+		key, err := os.ReadFile(cfg.RS256PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		jwtConfig.RS256PublicKey = key
+	}
+
+	verifier, err := auth.NewJWTVerifier(jwtConfig)
+	if errors.Is(err, auth.ErrNoJWTKeys) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return verifier, nil
+}
+
+func setupLogger(env string) *slog.Logger {
+	var log *slog.Logger
+
+	switch env {
+	case envLocal:
+		log = slog.New(
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		)
+	case envDev:
+		log = slog.New(
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		)
+	case envProd:
+		log = slog.New(
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		)
+	default:
+		log = slog.New(
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		)
+	}
+
+	return log
+}
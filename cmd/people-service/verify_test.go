@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"people-service/config"
+	"people-service/internal/data-prep/age"
+	"people-service/internal/data-prep/enrich"
+	"people-service/internal/data-prep/gender"
+	"people-service/internal/data-prep/nationality"
+	"people-service/internal/http-server/openapi"
+	"people-service/internal/lib/confirm"
+	"people-service/internal/lib/csvimport"
+	"people-service/internal/lib/health"
+
+	"github.com/go-chi/chi"
+)
+
+// newTestRouter builds the router of the service without a database. Routes
+// are only walked or answered by middleware, so handlers never reach it.
+func newTestRouter(t *testing.T, apiSpec *openapi.Document) *chi.Mux {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cfg := &config.Config{
+		Pagination: config.Pagination{DefaultSize: 20, MaxSize: 100},
+		Batch:      config.Batch{Mode: "atomic", MaxItems: 10},
+		Auth:       config.Auth{Required: false, AnonymousRole: "reader"},
+	}
+
+	ageService := age.New(log, "http://127.0.0.1:0/", 0)
+	genderService := gender.New(log, "http://127.0.0.1:0/", 0)
+	nationalityService := nationality.New(log, "http://127.0.0.1:0/", 0)
+	enricher := enrich.New(log, ageService, genderService, nationalityService)
+
+	confirmer, err := confirm.New("", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router, err := setupRouter(log, cfg, apiSpec, services{
+		ageService:         ageService,
+		genderService:      genderService,
+		nationalityService: nationalityService,
+		enricher:           enricher,
+		confirmer:          confirmer,
+		importer:           csvimport.New(nil, enricher),
+		importReports:      csvimport.NewReports(time.Hour),
+		checker:            health.NewChecker(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return router
+}
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	apiSpec := openapi.New()
+	router := newTestRouter(t, apiSpec)
+
+	if err := openapi.Verify(router, apiSpec); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyDetectsDrift(t *testing.T) {
+	t.Run("undocumented route", func(t *testing.T) {
+		apiSpec := openapi.New()
+		router := newTestRouter(t, apiSpec)
+		router.Get("/v2/person/undocumented", func(w http.ResponseWriter, r *http.Request) {})
+
+		if err := openapi.Verify(router, apiSpec); err == nil {
+			t.Fatal("expected an error for a route missing from the document")
+		}
+	})
+
+	t.Run("route not served", func(t *testing.T) {
+		apiSpec := openapi.New()
+		router := newTestRouter(t, apiSpec)
+		apiSpec.Paths["/person/unserved"] = openapi.PathItem{"get": &openapi.Operation{}}
+
+		if err := openapi.Verify(router, apiSpec); err == nil {
+			t.Fatal("expected an error for a documented route that is not served")
+		}
+	})
+}
+
+func TestVersionPrefixes(t *testing.T) {
+	router := newTestRouter(t, openapi.New())
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/person?age=abc", http.StatusBadRequest},
+		{"/v1/person?age=abc", http.StatusBadRequest},
+		{"/v2/person?age=abc", http.StatusBadRequest},
+		{"/v3/person?age=abc", http.StatusNotFound},
+		{"/v0/person?age=abc", http.StatusNotFound},
+		{"/api/person?age=abc", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		if rec.Code != tt.status {
+			t.Errorf("GET %s: got status %d, want %d", tt.target, rec.Code, tt.status)
+		}
+	}
+}
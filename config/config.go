@@ -1,79 +1,367 @@
-package config
-
-import (
-	"fmt"
-	"os"
-	"strconv"
-	"time"
-)
-
-type Config struct {
-	Env                   string
-	AgeServiceUrl         string
-	NationalityServiceUrl string
-	GenderServiceUrl      string
-	CtxTimeout            int
-	Storage               StorageConfig
-	HTTPServer            HTTPServer
-}
-
-type HTTPServer struct {
-	Address     string
-	Timeout     time.Duration
-	IdleTimeout time.Duration
-}
-
-type StorageConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-}
-
-func MustLoad() *Config {
-	var cfg Config
-	var err error
-
-	cfg.Env = os.Getenv("PS_ENV")
-
-	cfg.AgeServiceUrl = os.Getenv("PS_AGE_URL")
-	cfg.GenderServiceUrl = os.Getenv("PS_GENDER_URL")
-	cfg.NationalityServiceUrl = os.Getenv("PS_NATIONALITY_URL")
-
-	cfg.CtxTimeout, err = strconv.Atoi(loadConfig("PS_CTX_TIMEOUT"))
-	if err != nil {
-		panic(fmt.Sprintf("cannot load ctx timeout config: %s", err))
-	}
-
-	cfg.Storage.Host = loadConfig("PS_PG_DB_HOST")
-	cfg.Storage.Port, err = strconv.Atoi(loadConfig("PS_PG_DB_PORT"))
-	if err != nil {
-		panic(fmt.Sprintf("cannot load db port config: %s", err))
-	}
-	cfg.Storage.DBName = loadConfig("PS_PG_DB_NAME")
-	cfg.Storage.User = loadConfig("PS_PG_DB_USER")
-	cfg.Storage.Password = loadConfig("PS_PG_DB_PASS")
-
-	cfg.HTTPServer.Address = loadConfig("PS_HTTP_SERVER")
-	cfg.HTTPServer.Timeout, err = time.ParseDuration(loadConfig("PS_HTTP_TIMEOUT"))
-	if err != nil {
-		panic(fmt.Sprintf("cannot load server timeout config: %s", err))
-	}
-	cfg.HTTPServer.IdleTimeout, err = time.ParseDuration(loadConfig("PS_HTTP_IDLE_TIMEOUT"))
-	if err != nil {
-		panic(fmt.Sprintf("cannot load server idle timeout config: %s", err))
-	}
-
-	return &cfg
-}
-
-func loadConfig(name string) string {
-	cfg, exists := os.LookupEnv(name)
-
-	if !exists {
-		panic(fmt.Sprintf("env variable does not exist: %s", name))
-	}
-
-	return cfg
-}
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	identityDisabled      = "none"
+	defaultIdentityFields = "name,surname,patronymic"
+
+	defaultPageSize = "20"
+	maxPageSize     = "100"
+
+	defaultBatchMode     = "atomic"
+	defaultBatchMaxItems = "1000"
+
+	defaultValidateResponses = "false"
+
+	defaultIdempotencyTTL   = "24h"
+	defaultIdempotencyLease = "5m"
+
+	defaultConfirmTTL = "5m"
+
+	defaultRateLimitRead   = "600/1m"
+	defaultRateLimitWrite  = "120/1m"
+	defaultRateLimitEnrich = "30/1m"
+
+	defaultAuthRequired      = "true"
+	defaultAuthAnonymousRole = "reader"
+
+	defaultPGConnectTimeout = "5s"
+
+	defaultReadyCheckUpstreams = "false"
+	defaultReadyUpstreamCache  = "30s"
+	defaultShutdownDelay       = "0s"
+
+	defaultEnrichCacheTTL = "1h"
+
+	defaultTraceExporter    = "none"
+	defaultTraceSampleRatio = "1"
+)
+
+var identityFields = map[string]bool{
+	"name":       true,
+	"surname":    true,
+	"patronymic": true,
+}
+
+type Config struct {
+	Env                   string
+	AgeServiceUrl         string
+	NationalityServiceUrl string
+	GenderServiceUrl      string
+	CtxTimeout            int
+	EnrichCacheTTL        time.Duration
+	Storage               StorageConfig
+	HTTPServer            HTTPServer
+	Pagination            Pagination
+	Batch                 Batch
+	ConfirmSecret         string
+	ConfirmTTL            time.Duration
+	ValidateResponses     bool
+	V1Sunset              time.Time
+	IdempotencyTTL        time.Duration
+	IdempotencyLease      time.Duration
+	Auth                  Auth
+	RateLimits            RateLimits
+	Health                Health
+	Tracing               Tracing
+}
+
+// Tracing selects where spans go: none, otlp (configured by the standard
+// OTEL_EXPORTER_OTLP_* variables), stdout, or file, appending to File.
+type Tracing struct {
+	Exporter    string
+	File        string
+	SampleRatio float64
+}
+
+// Health configures readiness. CheckUpstreams makes readiness depend on the
+// data-prep services too; their results are reused for UpstreamCacheTTL.
+// ShutdownDelay is how long the service keeps serving after it reports not
+// ready on shutdown, letting the orchestrator stop routing to it.
+type Health struct {
+	CheckUpstreams   bool
+	UpstreamCacheTTL time.Duration
+	ShutdownDelay    time.Duration
+}
+
+// RateLimits are the requests a client can make to routes that read people,
+// change them, and look attributes up upstream.
+type RateLimits struct {
+	Read   RateLimit
+	Write  RateLimit
+	Enrich RateLimit
+}
+
+// RateLimit allows Requests requests per Per; zero Requests means no limit.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+type Auth struct {
+	Required      bool
+	AnonymousRole string
+	JWT           JWT
+}
+
+type JWT struct {
+	HS256Secret        string
+	RS256PublicKeyFile string
+	Issuer             string
+	Audience           string
+}
+
+type Batch struct {
+	Mode     string
+	MaxItems int
+}
+
+type Pagination struct {
+	DefaultSize uint
+	MaxSize     uint
+}
+
+type HTTPServer struct {
+	Address     string
+	Timeout     time.Duration
+	IdleTimeout time.Duration
+}
+
+type StorageConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+
+	ConnectTimeout time.Duration
+
+	IdentityFields []string
+}
+
+func MustLoad() *Config {
+	var cfg Config
+	var err error
+
+	cfg.Env = os.Getenv("PS_ENV")
+
+	cfg.AgeServiceUrl = os.Getenv("PS_AGE_URL")
+	cfg.GenderServiceUrl = os.Getenv("PS_GENDER_URL")
+	cfg.NationalityServiceUrl = os.Getenv("PS_NATIONALITY_URL")
+
+	cfg.CtxTimeout, err = strconv.Atoi(loadConfig("PS_CTX_TIMEOUT"))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load ctx timeout config: %s", err))
+	}
+
+	cfg.EnrichCacheTTL, err = time.ParseDuration(loadConfigOrDefault("PS_ENRICH_CACHE_TTL", defaultEnrichCacheTTL))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load enrichment cache ttl config: %s", err))
+	}
+
+	cfg.Storage.Host = loadConfig("PS_PG_DB_HOST")
+	cfg.Storage.Port, err = strconv.Atoi(loadConfig("PS_PG_DB_PORT"))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load db port config: %s", err))
+	}
+	cfg.Storage.DBName = loadConfig("PS_PG_DB_NAME")
+	cfg.Storage.User = loadConfig("PS_PG_DB_USER")
+	cfg.Storage.Password = loadConfig("PS_PG_DB_PASS")
+	cfg.Storage.ConnectTimeout, err = time.ParseDuration(loadConfigOrDefault("PS_PG_CONNECT_TIMEOUT", defaultPGConnectTimeout))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load db connect timeout config: %s", err))
+	}
+	cfg.Storage.IdentityFields, err = parseIdentityFields(loadConfigOrDefault("PS_PERSON_IDENTITY", defaultIdentityFields))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load person identity config: %s", err))
+	}
+
+	cfg.HTTPServer.Address = loadConfig("PS_HTTP_SERVER")
+	cfg.HTTPServer.Timeout, err = time.ParseDuration(loadConfig("PS_HTTP_TIMEOUT"))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load server timeout config: %s", err))
+	}
+	cfg.HTTPServer.IdleTimeout, err = time.ParseDuration(loadConfig("PS_HTTP_IDLE_TIMEOUT"))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load server idle timeout config: %s", err))
+	}
+
+	cfg.Pagination.DefaultSize, err = parseUint(loadConfigOrDefault("PS_PAGE_SIZE_DEFAULT", defaultPageSize))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load default page size config: %s", err))
+	}
+	cfg.Pagination.MaxSize, err = parseUint(loadConfigOrDefault("PS_PAGE_SIZE_MAX", maxPageSize))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load max page size config: %s", err))
+	}
+	if cfg.Pagination.DefaultSize == 0 || cfg.Pagination.DefaultSize > cfg.Pagination.MaxSize {
+		panic("default page size must be between 1 and max page size")
+	}
+
+	cfg.Batch.Mode = loadConfigOrDefault("PS_BATCH_MODE", defaultBatchMode)
+	if cfg.Batch.Mode != "atomic" && cfg.Batch.Mode != "best_effort" {
+		panic(fmt.Sprintf("unknown batch mode: %s", cfg.Batch.Mode))
+	}
+	cfg.ConfirmSecret = os.Getenv("PS_CONFIRM_SECRET")
+
+	cfg.ConfirmTTL, err = time.ParseDuration(loadConfigOrDefault("PS_CONFIRM_TTL", defaultConfirmTTL))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load confirm token ttl config: %s", err))
+	}
+
+	cfg.Batch.MaxItems, err = strconv.Atoi(loadConfigOrDefault("PS_BATCH_MAX_ITEMS", defaultBatchMaxItems))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load batch max items config: %s", err))
+	}
+
+	cfg.ValidateResponses, err = strconv.ParseBool(loadConfigOrDefault("PS_VALIDATE_RESPONSES", defaultValidateResponses))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load response validation config: %s", err))
+	}
+
+	cfg.IdempotencyTTL, err = time.ParseDuration(loadConfigOrDefault("PS_IDEMPOTENCY_TTL", defaultIdempotencyTTL))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load idempotency ttl config: %s", err))
+	}
+
+	cfg.IdempotencyLease, err = time.ParseDuration(loadConfigOrDefault("PS_IDEMPOTENCY_LEASE", defaultIdempotencyLease))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load idempotency lease config: %s", err))
+	}
+
+	cfg.Auth.Required, err = strconv.ParseBool(loadConfigOrDefault("PS_AUTH_REQUIRED", defaultAuthRequired))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load auth required config: %s", err))
+	}
+	cfg.Auth.AnonymousRole = loadConfigOrDefault("PS_AUTH_ANONYMOUS_ROLE", defaultAuthAnonymousRole)
+	if cfg.Auth.AnonymousRole != "reader" && cfg.Auth.AnonymousRole != "editor" && cfg.Auth.AnonymousRole != "admin" {
+		panic(fmt.Sprintf("unknown anonymous role: %s", cfg.Auth.AnonymousRole))
+	}
+	cfg.Auth.JWT.HS256Secret = os.Getenv("PS_JWT_HS256_SECRET")
+	cfg.Auth.JWT.RS256PublicKeyFile = os.Getenv("PS_JWT_RS256_PUBLIC_KEY_FILE")
+	cfg.Auth.JWT.Issuer = os.Getenv("PS_JWT_ISSUER")
+	cfg.Auth.JWT.Audience = os.Getenv("PS_JWT_AUDIENCE")
+
+	cfg.RateLimits.Read, err = parseRateLimit(loadConfigOrDefault("PS_RATE_LIMIT_READ", defaultRateLimitRead))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load read rate limit config: %s", err))
+	}
+	cfg.RateLimits.Write, err = parseRateLimit(loadConfigOrDefault("PS_RATE_LIMIT_WRITE", defaultRateLimitWrite))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load write rate limit config: %s", err))
+	}
+	cfg.RateLimits.Enrich, err = parseRateLimit(loadConfigOrDefault("PS_RATE_LIMIT_ENRICH", defaultRateLimitEnrich))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load enrich rate limit config: %s", err))
+	}
+
+	cfg.Health.CheckUpstreams, err = strconv.ParseBool(loadConfigOrDefault("PS_READY_CHECK_UPSTREAMS", defaultReadyCheckUpstreams))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load readiness upstream check config: %s", err))
+	}
+	cfg.Health.UpstreamCacheTTL, err = time.ParseDuration(loadConfigOrDefault("PS_READY_UPSTREAM_CACHE", defaultReadyUpstreamCache))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load readiness upstream cache config: %s", err))
+	}
+	cfg.Health.ShutdownDelay, err = time.ParseDuration(loadConfigOrDefault("PS_SHUTDOWN_DELAY", defaultShutdownDelay))
+	if err != nil {
+		panic(fmt.Sprintf("cannot load shutdown delay config: %s", err))
+	}
+
+	cfg.Tracing.Exporter = loadConfigOrDefault("PS_TRACE_EXPORTER", defaultTraceExporter)
+	switch cfg.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		cfg.Tracing.File = loadConfig("PS_TRACE_FILE")
+	default:
+		panic(fmt.Sprintf("unknown trace exporter: %s", cfg.Tracing.Exporter))
+	}
+	cfg.Tracing.SampleRatio, err = strconv.ParseFloat(loadConfigOrDefault("PS_TRACE_SAMPLE_RATIO", defaultTraceSampleRatio), 64)
+	if err != nil || cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		panic(fmt.Sprintf("trace sample ratio must be a number in [0, 1]: %s", os.Getenv("PS_TRACE_SAMPLE_RATIO")))
+	}
+
+	if v := os.Getenv("PS_V1_SUNSET"); v != "" {
+		cfg.V1Sunset, err = time.Parse(time.DateOnly, v)
+		if err != nil {
+			panic(fmt.Sprintf("cannot load v1 sunset config: %s", err))
+		}
+	}
+
+	return &cfg
+}
+
+func loadConfig(name string) string {
+	cfg, exists := os.LookupEnv(name)
+
+	if !exists {
+		panic(fmt.Sprintf("env variable does not exist: %s", name))
+	}
+
+	return cfg
+}
+
+func loadConfigOrDefault(name string, def string) string {
+	cfg, exists := os.LookupEnv(name)
+
+	if !exists {
+		return def
+	}
+
+	return cfg
+}
+
+func parseIdentityFields(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == identityDisabled {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, f := range strings.Split(value, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if !identityFields[f] {
+			return nil, fmt.Errorf("unknown identity field: %q", f)
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// parseRateLimit reads a limit written as requests/period, e.g. 600/1m, or
+// off.
+func parseRateLimit(value string) (RateLimit, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" {
+		return RateLimit{}, nil
+	}
+
+	requests, per, found := strings.Cut(value, "/")
+	if !found {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, want requests/period", value)
+	}
+
+	var limit RateLimit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit requests %q", requests)
+	}
+	if limit.Per, err = time.ParseDuration(per); err != nil || limit.Per <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit period %q", per)
+	}
+
+	return limit, nil
+}
+
+func parseUint(value string) (uint, error) {
+	n, err := strconv.ParseUint(value, 10, 0)
+	return uint(n), err
+}
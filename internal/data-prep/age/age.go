@@ -1,64 +1,151 @@
-package age
-
-import (
-	"encoding/json"
-	"log/slog"
-	"net/http"
-)
-
-type Request struct {
-	Name string `json:"name" validate:"required"`
-}
-
-type Response struct {
-	//resp.Response
-	Count int    `json:"count,omitempty"`
-	Name  string `json:"name,omitempty"`
-	Age   int    `json:"age" validate:"required"`
-}
-
-type AgeService struct {
-	log     *slog.Logger
-	baseUrl string
-}
-
-func New(log *slog.Logger, url string) *AgeService {
-	return &AgeService{log: log, baseUrl: url}
-}
-
-func (a *AgeService) GetAge(name string) (int, error) {
-	const op = "data-prep.age.GetAge"
-
-	log := a.log.With(
-		slog.String("op", op),
-	)
-
-	req, err := http.NewRequest("GET", a.baseUrl, nil)
-	if err != nil {
-		log.Error("cannot form new request")
-		return 0, err
-	}
-
-	q := req.URL.Query()
-	q.Add("name", name)
-	req.URL.RawQuery = q.Encode()
-	//req = req.WithContext(ctx)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Error("error while making request")
-		return 0, err
-	}
-	defer resp.Body.Close()
-
-	var ageResp Response
-	err = json.NewDecoder(resp.Body).Decode(&ageResp)
-	if err != nil {
-		log.Error("cannot decode response")
-		return 0, err
-	}
-
-	return ageResp.Age, nil
-
-}
+package age
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"people-service/internal/data-prep/cache"
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/metrics"
+	"people-service/internal/lib/tracing"
+)
+
+// batchSize is the largest number of names the service accepts at once.
+const batchSize = 10
+
+type Request struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type Response struct {
+	//resp.Response
+	Count int    `json:"count,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Age   int    `json:"age" validate:"required"`
+}
+
+type AgeService struct {
+	log     *slog.Logger
+	baseUrl string
+	client  *http.Client
+	cache   *cache.Cache[int]
+}
+
+// New returns the service; the ages it finds are cached for cacheTTL.
+func New(log *slog.Logger, url string, cacheTTL time.Duration) *AgeService {
+	return &AgeService{
+		log:     log,
+		baseUrl: url,
+		client:  &http.Client{Transport: tracing.Transport(models.SourceAgify, metrics.Transport(models.SourceAgify, http.DefaultTransport))},
+		cache:   cache.New[int](models.SourceAgify, cacheTTL),
+	}
+}
+
+func (a *AgeService) GetAge(ctx context.Context, name string) (int, error) {
+	const op = "data-prep.age.GetAge"
+
+	log := a.log.With(
+		slog.String("op", op),
+		sl.TraceID(ctx),
+	)
+
+	if v, ok := a.cache.Get(name); ok {
+		return v, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseUrl, nil)
+	if err != nil {
+		log.Error("cannot form new request")
+		return 0, err
+	}
+
+	q := req.URL.Query()
+	q.Add("name", name)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		log.Error("error while making request")
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var ageResp Response
+	err = json.NewDecoder(resp.Body).Decode(&ageResp)
+	if err != nil {
+		log.Error("cannot decode response")
+		return 0, err
+	}
+
+	if ageResp.Age > 0 {
+		a.cache.Put(name, ageResp.Age)
+	}
+
+	return ageResp.Age, nil
+
+}
+
+// GetAges returns ages of the names, querying the service
+// batchSize names at a time. Names the service has no age for are left out.
+func (a *AgeService) GetAges(ctx context.Context, names []string) (map[string]int, error) {
+	const op = "data-prep.age.GetAges"
+
+	log := a.log.With(
+		slog.String("op", op),
+		sl.TraceID(ctx),
+	)
+
+	result := make(map[string]int, len(names))
+
+	var missing []string
+	for _, name := range names {
+		if v, ok := a.cache.Get(name); ok {
+			result[name] = v
+		} else {
+			missing = append(missing, name)
+		}
+	}
+
+	for start := 0; start < len(missing); start += batchSize {
+		end := min(start+batchSize, len(missing))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseUrl, nil)
+		if err != nil {
+			log.Error("cannot form new request")
+			return nil, err
+		}
+
+		q := req.URL.Query()
+		for _, name := range missing[start:end] {
+			q.Add("name[]", name)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := a.client.Do(req)
+		if err != nil {
+			log.Error("error while making request")
+			return nil, err
+		}
+
+		var ageResp []Response
+		err = json.NewDecoder(resp.Body).Decode(&ageResp)
+		resp.Body.Close()
+		if err != nil {
+			log.Error("cannot decode response")
+			return nil, err
+		}
+
+		for _, r := range ageResp {
+			if r.Age > 0 {
+				result[r.Name] = r.Age
+				a.cache.Put(r.Name, r.Age)
+			}
+		}
+	}
+
+	return result, nil
+}
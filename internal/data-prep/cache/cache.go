@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"people-service/internal/lib/metrics"
+)
+
+// maxEntries bounds the memory a cache takes; names are not cached once it
+// is full of entries that have not expired.
+const maxEntries = 10000
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// Cache keeps what an enrichment provider answered for names, so that
+// popular names are not looked up again until ttl passes. A zero ttl
+// disables it.
+type Cache[V any] struct {
+	provider string
+	ttl      time.Duration
+	mu       sync.Mutex
+	entries  map[string]entry[V]
+}
+
+func New[V any](provider string, ttl time.Duration) *Cache[V] {
+	return &Cache[V]{provider: provider, ttl: ttl, entries: make(map[string]entry[V])}
+}
+
+func (c *Cache[V]) Get(name string) (V, bool) {
+	var zero V
+	if c.ttl <= 0 {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	c.mu.Unlock()
+
+	if !ok || time.Now().After(e.expires) {
+		metrics.EnrichCache.WithLabelValues(c.provider, metrics.CacheMiss).Inc()
+		return zero, false
+	}
+	metrics.EnrichCache.WithLabelValues(c.provider, metrics.CacheHit).Inc()
+	return e.value, true
+}
+
+func (c *Cache[V]) Put(name string, value V) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxEntries {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxEntries {
+			return
+		}
+	}
+	c.entries[name] = entry[V]{value: value, expires: now.Add(c.ttl)}
+}
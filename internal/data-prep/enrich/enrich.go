@@ -0,0 +1,114 @@
+package enrich
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/translit"
+)
+
+type AgesGetter interface {
+	GetAges(ctx context.Context, names []string) (map[string]int, error)
+}
+
+type GendersGetter interface {
+	GetGenders(ctx context.Context, names []string) (map[string]string, error)
+}
+
+type NationalitiesGetter interface {
+	GetNationalities(ctx context.Context, names []string) (map[string]string, error)
+}
+
+// Enricher fills in missing attributes of many people at once, asking each
+// upstream service about every distinct name only once.
+type Enricher struct {
+	log                 *slog.Logger
+	agesGetter          AgesGetter
+	gendersGetter       GendersGetter
+	nationalitiesGetter NationalitiesGetter
+}
+
+func New(log *slog.Logger,
+	agesGetter AgesGetter,
+	gendersGetter GendersGetter,
+	nationalitiesGetter NationalitiesGetter,
+) *Enricher {
+	return &Enricher{
+		log:                 log,
+		agesGetter:          agesGetter,
+		gendersGetter:       gendersGetter,
+		nationalitiesGetter: nationalitiesGetter,
+	}
+}
+
+// Enrich sets age, gender and nationality of the people that lack them.
+// Upstream failures are logged and leave the attributes empty.
+func (e *Enricher) Enrich(ctx context.Context, people []models.Person) {
+	const op = "data-prep.enrich.Enrich"
+
+	log := e.log.With(
+		slog.String("op", op),
+		sl.TraceID(ctx),
+	)
+
+	names := distinctNames(people, func(p models.Person) bool { return p.Age == 0 })
+	ages, err := e.agesGetter.GetAges(ctx, names)
+	if err != nil {
+		log.Error("failed to get ages", sl.Err(err))
+	}
+
+	names = distinctNames(people, func(p models.Person) bool { return p.Gender == "" })
+	genders, err := e.gendersGetter.GetGenders(ctx, names)
+	if err != nil {
+		log.Error("failed to get genders", sl.Err(err))
+	}
+
+	names = distinctNames(people, func(p models.Person) bool { return p.Nationality == "" })
+	nationalities, err := e.nationalitiesGetter.GetNationalities(ctx, names)
+	if err != nil {
+		log.Error("failed to get nationalities", sl.Err(err))
+	}
+
+	now := time.Now()
+	for i := range people {
+		p := &people[i]
+		enriched := false
+
+		name := translit.Latin(p.Name)
+
+		if age, ok := ages[name]; ok && p.Age == 0 {
+			p.Age, p.AgeSource = age, models.SourceAgify
+			enriched = true
+		}
+		if gender, ok := genders[name]; ok && p.Gender == "" {
+			p.Gender, p.GenderSource = gender, models.SourceGenderize
+			enriched = true
+		}
+		if nationality, ok := nationalities[name]; ok && p.Nationality == "" {
+			p.Nationality, p.NationalitySource = nationality, models.SourceNationalize
+			enriched = true
+		}
+
+		if enriched {
+			p.EnrichedAt = &now
+		}
+	}
+}
+
+// distinctNames lists the Latin forms of the names of people missing an
+// attribute, since upstream services know names in Latin script.
+func distinctNames(people []models.Person, missing func(models.Person) bool) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range people {
+		name := translit.Latin(p.Name)
+		if missing(p) && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -1,63 +1,151 @@
-package gender
-
-import (
-	"encoding/json"
-	"log/slog"
-	"net/http"
-)
-
-type Request struct {
-	Name string `json:"name" validate:"required"`
-}
-
-type Response struct {
-	Count       int     `json:"count,omitempty"`
-	Name        string  `json:"name,omitempty"`
-	Gender      string  `json:"gender,omitempty"`
-	Probability float32 `json:"probability,omitempty"`
-}
-
-type GenderService struct {
-	log     *slog.Logger
-	baseUrl string
-}
-
-func New(log *slog.Logger, url string) *GenderService {
-	return &GenderService{log: log, baseUrl: url}
-}
-
-func (a *GenderService) GetGender(name string) (string, error) {
-	const op = "data-prep.gender.GetGender"
-
-	log := a.log.With(
-		slog.String("op", op),
-	)
-
-	req, err := http.NewRequest("GET", a.baseUrl, nil)
-	if err != nil {
-		log.Error("cannot form new request")
-		return "", err
-	}
-
-	q := req.URL.Query()
-	q.Add("name", name)
-	req.URL.RawQuery = q.Encode()
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Error("error while making request")
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var gResp Response
-	err = json.NewDecoder(resp.Body).Decode(&gResp)
-	if err != nil {
-		log.Error("cannot decode response")
-		return "", err
-	}
-
-	return gResp.Gender, nil
-
-}
+package gender
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"people-service/internal/data-prep/cache"
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/metrics"
+	"people-service/internal/lib/tracing"
+)
+
+// batchSize is the largest number of names the service accepts at once.
+const batchSize = 10
+
+type Request struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type Response struct {
+	Count       int     `json:"count,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	Gender      string  `json:"gender,omitempty"`
+	Probability float32 `json:"probability,omitempty"`
+}
+
+type GenderService struct {
+	log     *slog.Logger
+	baseUrl string
+	client  *http.Client
+	cache   *cache.Cache[string]
+}
+
+// New returns the service; the genders it finds are cached for cacheTTL.
+func New(log *slog.Logger, url string, cacheTTL time.Duration) *GenderService {
+	return &GenderService{
+		log:     log,
+		baseUrl: url,
+		client:  &http.Client{Transport: tracing.Transport(models.SourceGenderize, metrics.Transport(models.SourceGenderize, http.DefaultTransport))},
+		cache:   cache.New[string](models.SourceGenderize, cacheTTL),
+	}
+}
+
+func (a *GenderService) GetGender(ctx context.Context, name string) (string, error) {
+	const op = "data-prep.gender.GetGender"
+
+	log := a.log.With(
+		slog.String("op", op),
+		sl.TraceID(ctx),
+	)
+
+	if v, ok := a.cache.Get(name); ok {
+		return v, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseUrl, nil)
+	if err != nil {
+		log.Error("cannot form new request")
+		return "", err
+	}
+
+	q := req.URL.Query()
+	q.Add("name", name)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		log.Error("error while making request")
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var gResp Response
+	err = json.NewDecoder(resp.Body).Decode(&gResp)
+	if err != nil {
+		log.Error("cannot decode response")
+		return "", err
+	}
+
+	if gResp.Gender != "" {
+		a.cache.Put(name, gResp.Gender)
+	}
+
+	return gResp.Gender, nil
+
+}
+
+// GetGenders returns genders of the names, querying the service
+// batchSize names at a time. Names the service has no gender for are left out.
+func (a *GenderService) GetGenders(ctx context.Context, names []string) (map[string]string, error) {
+	const op = "data-prep.gender.GetGenders"
+
+	log := a.log.With(
+		slog.String("op", op),
+		sl.TraceID(ctx),
+	)
+
+	result := make(map[string]string, len(names))
+
+	var missing []string
+	for _, name := range names {
+		if v, ok := a.cache.Get(name); ok {
+			result[name] = v
+		} else {
+			missing = append(missing, name)
+		}
+	}
+
+	for start := 0; start < len(missing); start += batchSize {
+		end := min(start+batchSize, len(missing))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseUrl, nil)
+		if err != nil {
+			log.Error("cannot form new request")
+			return nil, err
+		}
+
+		q := req.URL.Query()
+		for _, name := range missing[start:end] {
+			q.Add("name[]", name)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := a.client.Do(req)
+		if err != nil {
+			log.Error("error while making request")
+			return nil, err
+		}
+
+		var gResp []Response
+		err = json.NewDecoder(resp.Body).Decode(&gResp)
+		resp.Body.Close()
+		if err != nil {
+			log.Error("cannot decode response")
+			return nil, err
+		}
+
+		for _, r := range gResp {
+			if r.Gender != "" {
+				result[r.Name] = r.Gender
+				a.cache.Put(r.Name, r.Gender)
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -1,83 +1,171 @@
-package nationality
-
-import (
-	"encoding/json"
-	"errors"
-	"log/slog"
-	"net/http"
-	"sort"
-)
-
-type Request struct {
-	Name string `json:"name" validate:"required"`
-}
-
-type Response struct {
-	Count   int       `json:"count,omitempty"`
-	Name    string    `json:"name,omitempty"`
-	Country []Country `json:"country"`
-}
-
-type Country struct {
-	CountryId   string  `json:"country_id"`
-	Probability float32 `json:"probability"`
-}
-
-type NationalityService struct {
-	log     *slog.Logger
-	baseUrl string
-}
-
-func New(log *slog.Logger, url string) *NationalityService {
-	return &NationalityService{log: log, baseUrl: url}
-}
-
-func (a *NationalityService) GetNationality(name string) (string, error) {
-	const op = "data-prep.nationality.GetNationality"
-
-	log := a.log.With(
-		slog.String("op", op),
-	)
-
-	req, err := http.NewRequest("GET", a.baseUrl, nil)
-	if err != nil {
-		log.Error("cannot form new request")
-		return "", err
-	}
-
-	q := req.URL.Query()
-	q.Add("name", name)
-	req.URL.RawQuery = q.Encode()
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Error("error while making request")
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var nResp Response
-	err = json.NewDecoder(resp.Body).Decode(&nResp)
-	if err != nil {
-		log.Error("cannot decode response")
-		return "", err
-	}
-
-	if len(nResp.Country) < 1 {
-		log.Error("no country id found for the person")
-		return "", errors.New("no country id found for the person")
-	}
-
-	return getSingleNationality(nResp.Country), nil
-
-}
-
-func getSingleNationality(countries []Country) string {
-
-	sort.Slice(countries[:], func(i, j int) bool {
-		return countries[i].Probability > countries[j].Probability
-	})
-
-	return countries[0].CountryId
-}
+package nationality
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"people-service/internal/data-prep/cache"
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/metrics"
+	"people-service/internal/lib/tracing"
+)
+
+// batchSize is the largest number of names the service accepts at once.
+const batchSize = 10
+
+type Request struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type Response struct {
+	Count   int       `json:"count,omitempty"`
+	Name    string    `json:"name,omitempty"`
+	Country []Country `json:"country"`
+}
+
+type Country struct {
+	CountryId   string  `json:"country_id"`
+	Probability float32 `json:"probability"`
+}
+
+type NationalityService struct {
+	log     *slog.Logger
+	baseUrl string
+	client  *http.Client
+	cache   *cache.Cache[string]
+}
+
+// New returns the service; the nationalities it finds are cached for
+// cacheTTL.
+func New(log *slog.Logger, url string, cacheTTL time.Duration) *NationalityService {
+	return &NationalityService{
+		log:     log,
+		baseUrl: url,
+		client:  &http.Client{Transport: tracing.Transport(models.SourceNationalize, metrics.Transport(models.SourceNationalize, http.DefaultTransport))},
+		cache:   cache.New[string](models.SourceNationalize, cacheTTL),
+	}
+}
+
+func (a *NationalityService) GetNationality(ctx context.Context, name string) (string, error) {
+	const op = "data-prep.nationality.GetNationality"
+
+	log := a.log.With(
+		slog.String("op", op),
+		sl.TraceID(ctx),
+	)
+
+	if v, ok := a.cache.Get(name); ok {
+		return v, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseUrl, nil)
+	if err != nil {
+		log.Error("cannot form new request")
+		return "", err
+	}
+
+	q := req.URL.Query()
+	q.Add("name", name)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		log.Error("error while making request")
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var nResp Response
+	err = json.NewDecoder(resp.Body).Decode(&nResp)
+	if err != nil {
+		log.Error("cannot decode response")
+		return "", err
+	}
+
+	if len(nResp.Country) < 1 {
+		log.Error("no country id found for the person")
+		return "", errors.New("no country id found for the person")
+	}
+
+	nationality := getSingleNationality(nResp.Country)
+	a.cache.Put(name, nationality)
+
+	return nationality, nil
+
+}
+
+func getSingleNationality(countries []Country) string {
+
+	sort.Slice(countries[:], func(i, j int) bool {
+		return countries[i].Probability > countries[j].Probability
+	})
+
+	return countries[0].CountryId
+}
+
+// GetNationalities returns nationalities of the names, querying the service
+// batchSize names at a time. Names the service has no nationality for are left out.
+func (a *NationalityService) GetNationalities(ctx context.Context, names []string) (map[string]string, error) {
+	const op = "data-prep.nationality.GetNationalities"
+
+	log := a.log.With(
+		slog.String("op", op),
+		sl.TraceID(ctx),
+	)
+
+	result := make(map[string]string, len(names))
+
+	var missing []string
+	for _, name := range names {
+		if v, ok := a.cache.Get(name); ok {
+			result[name] = v
+		} else {
+			missing = append(missing, name)
+		}
+	}
+
+	for start := 0; start < len(missing); start += batchSize {
+		end := min(start+batchSize, len(missing))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseUrl, nil)
+		if err != nil {
+			log.Error("cannot form new request")
+			return nil, err
+		}
+
+		q := req.URL.Query()
+		for _, name := range missing[start:end] {
+			q.Add("name[]", name)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := a.client.Do(req)
+		if err != nil {
+			log.Error("error while making request")
+			return nil, err
+		}
+
+		var nResp []Response
+		err = json.NewDecoder(resp.Body).Decode(&nResp)
+		resp.Body.Close()
+		if err != nil {
+			log.Error("cannot decode response")
+			return nil, err
+		}
+
+		for _, r := range nResp {
+			if len(r.Country) > 0 {
+				result[r.Name] = getSingleNationality(r.Country)
+				a.cache.Put(r.Name, result[r.Name])
+			}
+		}
+	}
+
+	return result, nil
+}
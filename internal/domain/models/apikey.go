@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// APIKey describes an issued API key. The key itself is not kept.
+type APIKey struct {
+	Id        int        `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Role      string     `json:"role" db:"role"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
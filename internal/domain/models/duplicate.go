@@ -0,0 +1,8 @@
+package models
+
+// Duplicate is a person that is likely the same as another one.
+// Score is the trigram similarity of their folded names, from 0 to 1.
+type Duplicate struct {
+	Person Person  `json:"person" xml:"person"`
+	Score  float64 `json:"score" xml:"score"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// IdempotentResponse is the stored response to a request made with an
+// idempotency key, replayed when the request is repeated.
+type IdempotentResponse struct {
+	Status int
+	Header map[string]string
+	Body   []byte
+}
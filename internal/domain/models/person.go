@@ -1,11 +1,50 @@
-package models
-
-type Person struct {
-	Id          int
-	Name        string
-	Surname     string
-	Patronymic  string
-	Age         int
-	Gender      string
-	Nationality string
-}
+package models
+
+import "time"
+
+// Sources of person attributes.
+const (
+	SourceManual      = "manual"
+	SourceAgify       = "agify"
+	SourceGenderize   = "genderize"
+	SourceNationalize = "nationalize"
+)
+
+// Genders a person can have, as reported by the gender service.
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+)
+
+var Genders = []string{GenderMale, GenderFemale}
+
+// Limits of person fields, checked wherever people are written.
+const (
+	MaxNameLength = 255
+	MaxAge        = 150
+)
+
+// Person is a stored person. The fields people had from the start keep the
+// JSON keys clients already read, the Go field names.
+type Person struct {
+	Id          int    `xml:"id"`
+	Name        string `xml:"name"`
+	Surname     string `xml:"surname"`
+	Patronymic  string `xml:"patronymic"`
+	Age         int    `xml:"age"`
+	Gender      string `xml:"gender"`
+	Nationality string `xml:"nationality"`
+
+	// Latin forms of the names, transliterated from Cyrillic per ISO 9.
+	NameLatin       string `json:"name_latin" xml:"name_latin" db:"name_latin"`
+	SurnameLatin    string `json:"surname_latin" xml:"surname_latin" db:"surname_latin"`
+	PatronymicLatin string `json:"patronymic_latin" xml:"patronymic_latin" db:"patronymic_latin"`
+
+	AgeSource         string     `json:"age_source" xml:"age_source" db:"age_source"`
+	GenderSource      string     `json:"gender_source" xml:"gender_source" db:"gender_source"`
+	NationalitySource string     `json:"nationality_source" xml:"nationality_source" db:"nationality_source"`
+	EnrichedAt        *time.Time `json:"enriched_at,omitempty" xml:"enriched_at,omitempty" db:"enriched_at"`
+
+	CreatedAt time.Time `json:"created_at" xml:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" xml:"updated_at" db:"updated_at"`
+}
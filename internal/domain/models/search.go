@@ -0,0 +1,9 @@
+package models
+
+// SearchResult is a person found by a text query. Highlight is the full name,
+// HTML-escaped, with matched words wrapped in <mark> tags.
+type SearchResult struct {
+	Person    Person  `json:"person" xml:"person"`
+	Rank      float64 `json:"rank" xml:"rank"`
+	Highlight string  `json:"highlight" xml:"highlight"`
+}
@@ -0,0 +1,39 @@
+package models
+
+// Stats aggregates people matching a filter.
+type Stats struct {
+	Total         int                `json:"total"`
+	ByGender      []GenderCount      `json:"by_gender"`
+	ByNationality []NationalityStats `json:"by_nationality"`
+	ByAge         []AgeBucket        `json:"by_age"`
+	Coverage      Coverage           `json:"coverage"`
+}
+
+type GenderCount struct {
+	Gender string `json:"gender" db:"gender"`
+	Count  int    `json:"count" db:"count"`
+}
+
+// NationalityStats holds the age statistics of people with a known age.
+type NationalityStats struct {
+	Nationality string   `json:"nationality" db:"nationality"`
+	Count       int      `json:"count" db:"count"`
+	AvgAge      *float64 `json:"avg_age" db:"avg_age"`
+	MedianAge   *float64 `json:"median_age" db:"median_age"`
+}
+
+// AgeBucket counts people aged From to To inclusive.
+type AgeBucket struct {
+	From  int `json:"from" db:"from"`
+	To    int `json:"to" db:"to"`
+	Count int `json:"count" db:"count"`
+}
+
+// Coverage holds the percentage of people with each attribute known and of
+// people enriched from upstream services.
+type Coverage struct {
+	Age         float64 `json:"age"`
+	Gender      float64 `json:"gender"`
+	Nationality float64 `json:"nationality"`
+	Enriched    float64 `json:"enriched"`
+}
@@ -0,0 +1,18 @@
+package live
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	resp "people-service/internal/lib/api/response"
+)
+
+// New answers 200 for as long as the process serves requests. It checks no
+// dependencies, so that the orchestrator restarts the service only if it
+// hangs or dies.
+func New() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,67 @@
+package ready
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/health"
+	"people-service/internal/lib/logger/sl"
+)
+
+// timeout bounds the checks so that a hanging dependency fails the probe
+// rather than stalling it.
+const timeout = 5 * time.Second
+
+const checkPassed = "OK"
+
+type Response struct {
+	resp.Response
+	// Checks maps each check to OK or the reason it failed.
+	Checks map[string]string `json:"checks"`
+}
+
+type ReadinessChecker interface {
+	Ready(ctx context.Context) (bool, []health.Result)
+}
+
+// New answers 200 if the service is ready to serve requests and 503
+// otherwise, along with the outcome of each check.
+func New(log *slog.Logger, checker ReadinessChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.health.ready.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		ready, results := checker.Ready(ctx)
+
+		checks := make(map[string]string, len(results))
+		for _, result := range results {
+			checks[result.Name] = checkPassed
+			if result.Err != nil {
+				checks[result.Name] = result.Err.Error()
+			}
+		}
+
+		if !ready {
+			log.Warn("service is not ready", slog.Any("checks", checks))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, Response{Response: resp.Error("service is not ready"), Checks: checks})
+			return
+		}
+
+		render.JSON(w, r, Response{Response: resp.OK(), Checks: checks})
+	}
+}
@@ -0,0 +1,265 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/http-server/handlers/person/save"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/routing"
+	"people-service/internal/lib/validation"
+	"people-service/internal/storage"
+)
+
+// Modes of storing a batch.
+const (
+	ModeAtomic     = "atomic"
+	ModeBestEffort = "best_effort"
+)
+
+const contentTypeNDJSON = "application/x-ndjson"
+
+// maxItemSize bounds the body at 4 KiB per item, room for three names of 255
+// characters.
+const maxItemSize = 4 << 10
+
+// Config holds the defaults of the batch endpoint.
+type Config struct {
+	Mode     string
+	MaxItems int
+}
+
+// MaxBodySize is the largest body of a batch of cfg.MaxItems items.
+func MaxBodySize(cfg Config) int64 {
+	return int64(cfg.MaxItems) * maxItemSize
+}
+
+// Result mirrors the response of the single save handler for one item.
+// ConflictIndex is set instead of id when the item conflicts with another
+// item of an atomic batch, which was not saved either.
+type Result struct {
+	Index int `json:"index"`
+	save.Response
+	ConflictIndex *int `json:"conflict_index,omitempty"`
+}
+
+type Response struct {
+	resp.Response
+	Results []Result `json:"results"`
+}
+
+type PeopleSaver interface {
+	SavePeople(ctx context.Context, people []models.Person, atomic bool) ([]storage.SaveResult, error)
+}
+
+type Enricher interface {
+	Enrich(ctx context.Context, people []models.Person)
+}
+
+// New saves an array, or an NDJSON stream, of save.Request objects.
+func New(log *slog.Logger, peopleSaver PeopleSaver, enricher Enricher, cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.batch.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		mode := cfg.Mode
+		if v := r.URL.Query().Get(routing.ModeParam); v != "" {
+			mode = v
+		}
+		if mode != ModeAtomic && mode != ModeBestEffort {
+			log.Info("invalid batch mode", slog.String("mode", mode))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(fmt.Sprintf("mode must be %s or %s", ModeAtomic, ModeBestEffort)))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize(cfg))
+
+		reqs, err := decode(r, cfg.MaxItems)
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				render.Status(r, http.StatusRequestEntityTooLarge)
+			} else {
+				render.Status(r, http.StatusBadRequest)
+			}
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		log.Info("request body decoded", slog.Int("items", len(reqs)), slog.String("mode", mode))
+
+		results := make([]Result, len(reqs))
+		people := make([]models.Person, 0, len(reqs))
+		indexes := make([]int, 0, len(reqs))
+
+		for i, req := range reqs {
+			results[i].Index = i
+
+			req.Normalize()
+			if err := validation.Struct(req); err != nil {
+				results[i].Response.Response = resp.ValidationError(err.(validator.ValidationErrors))
+				continue
+			}
+
+			people = append(people, models.Person{
+				Name:       req.Name,
+				Surname:    req.Surname,
+				Patronymic: req.Patronymic,
+			})
+			indexes = append(indexes, i)
+		}
+
+		invalid := len(people) < len(reqs)
+		if invalid && mode == ModeAtomic {
+			for _, i := range indexes {
+				results[i].Response.Response = resp.Error("not saved: batch has invalid items")
+			}
+			log.Info("batch rejected", slog.Int("invalid", len(reqs)-len(people)))
+			respond(w, r, results, true)
+			return
+		}
+
+		if len(people) > 0 {
+			enricher.Enrich(r.Context(), people)
+
+			saved, err := peopleSaver.SavePeople(r.Context(), people, mode == ModeAtomic)
+			if err != nil {
+				log.Error("failed to add people", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to add people"))
+				return
+			}
+
+			for j, res := range saved {
+				results[indexes[j]].Response = saveResponse(res)
+
+				var conflictErr *storage.BatchConflictError
+				if errors.As(res.Err, &conflictErr) {
+					conflict := indexes[conflictErr.Index]
+					results[indexes[j]].ConflictIndex = &conflict
+				}
+			}
+		}
+
+		failed := 0
+		for _, res := range results {
+			if res.Status != resp.StatusOK {
+				failed++
+			}
+		}
+
+		log.Info("batch processed", slog.Int("saved", len(results)-failed), slog.Int("failed", failed))
+
+		respond(w, r, results, failed > 0)
+	}
+}
+
+// decode reads the items from a JSON array or, for application/x-ndjson
+// bodies, from a stream of JSON objects. Items are read one at a time, so a
+// body with too many of them fails as soon as it runs over maxItems.
+func decode(r *http.Request, maxItems int) ([]save.Request, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var reqs []save.Request
+	dec := json.NewDecoder(r.Body)
+	// NDJSON bodies are not checked against the API document on the way in.
+	dec.DisallowUnknownFields()
+	tooMany := fmt.Errorf("batch exceeds %d items", maxItems)
+
+	if mediaType == contentTypeNDJSON {
+		for {
+			var req save.Request
+			err := dec.Decode(&req)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", len(reqs), err)
+			}
+			if len(reqs) == maxItems {
+				return nil, tooMany
+			}
+			reqs = append(reqs, req)
+		}
+	} else {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty request")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return nil, errors.New("failed to decode request: body is not a JSON array")
+		}
+
+		for dec.More() {
+			if len(reqs) == maxItems {
+				return nil, tooMany
+			}
+			var req save.Request
+			if err := dec.Decode(&req); err != nil {
+				return nil, fmt.Errorf("item %d: %w", len(reqs), err)
+			}
+			reqs = append(reqs, req)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+	}
+
+	if len(reqs) == 0 {
+		return nil, errors.New("empty request")
+	}
+
+	return reqs, nil
+}
+
+func saveResponse(res storage.SaveResult) save.Response {
+	var existsErr *storage.ExistsError
+	var conflictErr *storage.BatchConflictError
+	switch {
+	case res.Err == nil:
+		return save.Response{Response: resp.OK(), Id: res.Id}
+	case errors.As(res.Err, &conflictErr):
+		return save.Response{Response: resp.Error("person already exists in the batch")}
+	case errors.As(res.Err, &existsErr):
+		return save.Response{Response: resp.Error("person already exists"), Id: existsErr.Id}
+	case errors.Is(res.Err, storage.ErrPersonExists):
+		return save.Response{Response: resp.Error("person already exists")}
+	case errors.Is(res.Err, storage.ErrBatchAborted):
+		return save.Response{Response: resp.Error("not saved: batch aborted")}
+	default:
+		return save.Response{Response: resp.Error("failed to add person")}
+	}
+}
+
+// respond replies 200 when every item is saved and 207 otherwise.
+func respond(w http.ResponseWriter, r *http.Request, results []Result, failed bool) {
+	response := Response{Response: resp.OK(), Results: results}
+	if failed {
+		response.Response = resp.Error("some people were not saved")
+		render.Status(r, http.StatusMultiStatus)
+	}
+	render.JSON(w, r, response)
+}
@@ -0,0 +1,126 @@
+package bulkdelete
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/confirm"
+	"people-service/internal/lib/logger/sl"
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/lib/routing"
+	"people-service/internal/storage"
+)
+
+const previewSize = 20
+
+type Response struct {
+	resp.Response
+	DryRun       bool            `json:"dry_run,omitempty"`
+	Affected     int             `json:"affected"`
+	ConfirmToken string          `json:"confirm_token,omitempty"`
+	Preview      []models.Person `json:"preview,omitempty"`
+}
+
+type PeopleDeleter interface {
+	GetPerson(ctx context.Context, params queryparam.Params) ([]models.Person, error)
+	CountPerson(ctx context.Context, params queryparam.Params) (int, error)
+	DeletePeople(ctx context.Context, params queryparam.Params, expected int) (int, error)
+}
+
+// New deletes every person matching the GET /person filters, of which at
+// least one is required. A dry run reports the affected people and a token
+// that must be passed as confirm to perform the same deletion.
+func New(log *slog.Logger, peopleDeleter PeopleDeleter, confirmer *confirm.Confirmer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.bulkdelete.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		query := r.URL.Query()
+		dryRun := query.Get(routing.DryRunParam) == "true"
+		token := query.Get(routing.ConfirmParam)
+		query.Del(routing.DryRunParam)
+		query.Del(routing.ConfirmParam)
+
+		qParams, err := queryparam.ParseFilters(query)
+		if err != nil {
+			log.Info("invalid query parameters", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		affected, err := peopleDeleter.CountPerson(r.Context(), qParams)
+		if err != nil {
+			log.Error("failed to count people", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to delete people"))
+			return
+		}
+
+		if dryRun {
+			qParams.Limit = previewSize
+			preview, err := peopleDeleter.GetPerson(r.Context(), qParams)
+			if err != nil {
+				log.Error("failed to get people", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to delete people"))
+				return
+			}
+
+			render.JSON(w, r, Response{
+				Response:     resp.OK(),
+				DryRun:       true,
+				Affected:     affected,
+				ConfirmToken: confirmer.Token(r.Method, query, nil, affected),
+				Preview:      preview,
+			})
+			return
+		}
+
+		if token == "" {
+			log.Info("confirmation token is missing")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("confirm token is required; get it with dry_run=true"))
+			return
+		}
+		if !confirmer.Valid(token, r.Method, query, nil, affected) {
+			log.Info("confirmation token does not match", slog.Int("affected", affected))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("confirm token does not match the request, has expired or the affected people changed; repeat the dry run"))
+			return
+		}
+
+		deleted, err := peopleDeleter.DeletePeople(r.Context(), qParams, affected)
+		if errors.Is(err, storage.ErrAffectedDiffer) {
+			log.Info("affected people changed", sl.Err(err))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("the affected people changed; repeat the dry run"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete people", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to delete people"))
+			return
+		}
+
+		log.Info("people deleted", slog.Int("affected", deleted))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Affected: deleted,
+		})
+	}
+}
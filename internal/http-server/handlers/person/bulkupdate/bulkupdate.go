@@ -0,0 +1,185 @@
+package bulkupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/confirm"
+	"people-service/internal/lib/logger/sl"
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/lib/routing"
+	"people-service/internal/lib/validation"
+	"people-service/internal/storage"
+)
+
+const previewSize = 20
+
+// Request lists the attributes to set. Names cannot be changed in bulk.
+type Request struct {
+	Age         int    `json:"age,omitempty" validate:"person_age"`
+	Gender      string `json:"gender,omitempty" validate:"omitempty,gender"`
+	Nationality string `json:"nationality,omitempty" validate:"omitempty,person_nationality"`
+}
+
+type Response struct {
+	resp.Response
+	DryRun       bool            `json:"dry_run,omitempty"`
+	Affected     int             `json:"affected"`
+	ConfirmToken string          `json:"confirm_token,omitempty"`
+	Preview      []models.Person `json:"preview,omitempty"`
+}
+
+type PeopleUpdater interface {
+	GetPerson(ctx context.Context, params queryparam.Params) ([]models.Person, error)
+	CountPerson(ctx context.Context, params queryparam.Params) (int, error)
+	UpdatePeople(ctx context.Context, params queryparam.Params, changes models.Person, expected int) (int, error)
+}
+
+// New updates every person matching the GET /person filters, of which at
+// least one is required. A dry run reports the affected people and a token
+// that must be passed as confirm to apply the same update.
+func New(log *slog.Logger, peopleUpdater PeopleUpdater, confirmer *confirm.Confirmer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.bulkupdate.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		query := r.URL.Query()
+		dryRun := query.Get(routing.DryRunParam) == "true"
+		token := query.Get(routing.ConfirmParam)
+		query.Del(routing.DryRunParam)
+		query.Del(routing.ConfirmParam)
+
+		qParams, err := queryparam.ParseFilters(query)
+		if err != nil {
+			log.Info("invalid query parameters", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		var req Request
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&req)
+
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("empty request"))
+			return
+		}
+
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request: only age, gender and nationality can be updated in bulk"))
+			return
+		}
+
+		if req == (Request{}) {
+			log.Info("nothing to update")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("nothing to update"))
+			return
+		}
+
+		if err := validation.Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Info("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		changes, _ := json.Marshal(req)
+
+		affected, err := peopleUpdater.CountPerson(r.Context(), qParams)
+		if err != nil {
+			log.Error("failed to count people", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to update people"))
+			return
+		}
+
+		if dryRun {
+			qParams.Limit = previewSize
+			preview, err := peopleUpdater.GetPerson(r.Context(), qParams)
+			if err != nil {
+				log.Error("failed to get people", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to update people"))
+				return
+			}
+
+			render.JSON(w, r, Response{
+				Response:     resp.OK(),
+				DryRun:       true,
+				Affected:     affected,
+				ConfirmToken: confirmer.Token(r.Method, query, changes, affected),
+				Preview:      preview,
+			})
+			return
+		}
+
+		if token == "" {
+			log.Info("confirmation token is missing")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("confirm token is required; get it with dry_run=true"))
+			return
+		}
+		if !confirmer.Valid(token, r.Method, query, changes, affected) {
+			log.Info("confirmation token does not match", slog.Int("affected", affected))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("confirm token does not match the request, has expired or the affected people changed; repeat the dry run"))
+			return
+		}
+
+		person := models.Person{Age: req.Age, Gender: req.Gender, Nationality: req.Nationality}
+		if person.Age != 0 {
+			person.AgeSource = models.SourceManual
+		}
+		if person.Gender != "" {
+			person.GenderSource = models.SourceManual
+		}
+		if person.Nationality != "" {
+			person.NationalitySource = models.SourceManual
+		}
+
+		updated, err := peopleUpdater.UpdatePeople(r.Context(), qParams, person, affected)
+		if errors.Is(err, storage.ErrAffectedDiffer) {
+			log.Info("affected people changed", sl.Err(err))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("the affected people changed; repeat the dry run"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to update people", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to update people"))
+			return
+		}
+
+		log.Info("people updated", slog.Int("affected", updated))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Affected: updated,
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package duplicates
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/api/content"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/export"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/rbac"
+	"people-service/internal/lib/routing"
+	"people-service/internal/storage"
+)
+
+const (
+	defaultThreshold = 0.4
+	defaultLimit     = 20
+	maxLimit         = 100
+)
+
+type Response struct {
+	resp.Response
+	Duplicates []models.Duplicate `json:"duplicates" xml:"duplicates>duplicate"`
+}
+
+type DuplicateFinder interface {
+	FindDuplicates(ctx context.Context, id int, threshold float64, limit int) ([]models.Duplicate, error)
+}
+
+func New(log *slog.Logger, duplicateFinder DuplicateFinder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.duplicates.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		idParam := chi.URLParam(r, routing.PersonIdParam)
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Info("error while parsing person id", slog.String("id", idParam))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid person id"))
+			return
+		}
+
+		threshold := defaultThreshold
+		if v := r.URL.Query().Get(routing.ThresholdParam); v != "" {
+			threshold, err = strconv.ParseFloat(v, 64)
+			if err != nil || threshold <= 0 || threshold > 1 {
+				log.Info("invalid threshold", slog.String("threshold", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("threshold must be a number in (0, 1]"))
+				return
+			}
+		}
+
+		limit := defaultLimit
+		if v := r.URL.Query().Get(routing.LimitParam); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 || limit > maxLimit {
+				log.Info("invalid limit", slog.String("limit", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("limit must be an integer in [1, 100]"))
+				return
+			}
+		}
+
+		duplicates, err := duplicateFinder.FindDuplicates(r.Context(), id, threshold, limit)
+		if errors.Is(err, storage.ErrPersonNotFound) {
+			log.Info("person not found", slog.Int("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("person not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to find duplicates", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to find duplicates"))
+			return
+		}
+
+		log.Info("duplicates found", slog.Int("id", id), slog.Int("count", len(duplicates)))
+
+		role := auth.RoleOf(r.Context())
+		people := make([]models.Person, len(duplicates))
+		for i := range duplicates {
+			rbac.Redact(role, &duplicates[i].Person)
+			people[i] = duplicates[i].Person
+		}
+
+		content.Render(w, r, content.Body{
+			Value: Response{
+				Response:   resp.OK(),
+				Duplicates: duplicates,
+			},
+			Root:    "response",
+			People:  people,
+			Columns: export.AllColumns(),
+		})
+	}
+}
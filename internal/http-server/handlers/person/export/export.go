@@ -0,0 +1,122 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/export"
+	"people-service/internal/lib/logger/sl"
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/lib/routing"
+)
+
+// flushEvery is the number of rows written between flushes of the response.
+const flushEvery = 100
+
+type PeopleExporter interface {
+	ExportPeople(ctx context.Context, params queryparam.Params, fn func(models.Person) error) error
+}
+
+// New streams the people matching the query in the requested format. Exports
+// can outlast the write timeout of the server, so the write deadline is moved
+// writeTimeout ahead whenever rows are flushed.
+func New(log *slog.Logger, peopleExporter PeopleExporter, writeTimeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.export.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		query := r.URL.Query()
+
+		format := query.Get(routing.FormatParam)
+		if format == "" {
+			format = export.FormatCSV
+		}
+		contentType := export.ContentType(format)
+		if contentType == "" {
+			log.Info("invalid export format", slog.String("format", format))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("format must be one of csv, ndjson, xlsx"))
+			return
+		}
+
+		columns, err := export.Columns(query.Get(routing.ColumnsParam))
+		if err != nil {
+			log.Info("invalid export columns", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		qParams, err := queryparam.Parse(query)
+		if err != nil {
+			log.Info("invalid query parameters", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="people.%s"`, format))
+
+		writer, err := export.NewWriter(format, w, columns)
+		if err != nil {
+			log.Error("failed to start export", sl.Err(err))
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		extendDeadline := func() {
+			if err := rc.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+				log.Debug("failed to extend write deadline", sl.Err(err))
+			}
+		}
+		extendDeadline()
+
+		rows := 0
+
+		err = peopleExporter.ExportPeople(r.Context(), qParams, func(p models.Person) error {
+			if err := writer.Write(p); err != nil {
+				return err
+			}
+			rows++
+			if rows%flushEvery == 0 {
+				rc.Flush()
+				extendDeadline()
+			}
+			return r.Context().Err()
+		})
+		if err != nil {
+			// The status line has already been sent, so the client only
+			// sees a truncated body.
+			if errors.Is(err, r.Context().Err()) {
+				log.Info("export cancelled", slog.Int("rows", rows))
+			} else {
+				log.Error("failed to export people", sl.Err(err), slog.Int("rows", rows))
+			}
+			return
+		}
+
+		// Closing writes the rest of an XLSX archive.
+		extendDeadline()
+		if err := writer.Close(); err != nil {
+			log.Error("failed to finish export", sl.Err(err))
+			return
+		}
+
+		log.Info("people exported", slog.String("format", format), slog.Int("rows", rows))
+	}
+}
@@ -1,45 +1,188 @@
-package get
-
-import (
-	"net/http"
-	"people-service/internal/domain/models"
-	resp "people-service/internal/lib/api/response"
-	queryparam "people-service/internal/lib/query-param"
-	"people-service/internal/lib/routing"
-
-	"log/slog"
-
-	"github.com/go-chi/render"
-)
-
-type PersonGetter interface {
-	GetPerson(params queryparam.Params) ([]models.Person, error)
-}
-
-func New(log *slog.Logger, personGetter PersonGetter) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.person.get.New"
-
-		qParams := queryparam.Params{}
-
-		qParams.Age = r.URL.Query().Get(routing.AgeParam)
-		qParams.Gender = r.URL.Query().Get(routing.GenderParam)
-		qParams.Id = r.URL.Query().Get(routing.IdParam)
-		qParams.Name = r.URL.Query().Get(routing.NameParam)
-		qParams.Surname = r.URL.Query().Get(routing.SurnameParam)
-		qParams.Patronymic = r.URL.Query().Get(routing.PatronymicParam)
-		qParams.Nationality = r.URL.Query().Get(routing.NationalityParam)
-		qParams.Offset = r.URL.Query().Get(routing.OffsetParam)
-		qParams.Limit = r.URL.Query().Get(routing.LimitParam)
-
-		persons, err := personGetter.GetPerson(qParams)
-		if err != nil {
-			log.Error("failed to get persons", err)
-			render.JSON(w, r, resp.Error("failed to get persons"))
-			return
-		}
-
-		render.JSON(w, r, persons)
-
-	}
-}
+package get
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/api/content"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/api/version"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/export"
+	"people-service/internal/lib/logger/sl"
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/lib/rbac"
+	"people-service/internal/lib/routing"
+	"strconv"
+	"strings"
+
+	"log/slog"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+// TotalCountHeader carries the number of matching people in version 1 JSON
+// responses, which are a bare array of people.
+const TotalCountHeader = "X-Total-Count"
+
+type Response struct {
+	resp.Response
+	// Items are people, or their projections when fields are requested.
+	Items      interface{} `json:"items" xml:"items>person"`
+	NextCursor string      `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty" xml:"prev_cursor,omitempty"`
+	Total      int         `json:"total" xml:"total"`
+}
+
+// PageSize limits the number of people returned per page.
+type PageSize struct {
+	Default uint
+	Max     uint
+}
+
+type PersonGetter interface {
+	GetPerson(ctx context.Context, params queryparam.Params) ([]models.Person, error)
+	CountPerson(ctx context.Context, params queryparam.Params) (int, error)
+}
+
+func New(log *slog.Logger, personGetter PersonGetter, pageSize PageSize) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.get.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		qParams, err := queryparam.Parse(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query parameters", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+		if params := rbac.Filters(auth.RoleOf(r.Context()), qParams); len(params) > 0 {
+			log.Info("enrichment-derived attributes may not be queried", slog.Any("params", params))
+			resp.RenderProblem(w, resp.Forbidden(r, "only the "+string(rbac.SeeEnriched)+" role can filter or sort by enrichment-derived attributes", params...))
+			return
+		}
+
+		fields := r.URL.Query().Get(routing.FieldsParam)
+		columns, err := export.Columns(fields)
+		if err != nil {
+			log.Info("invalid fields", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+		if fields != "" {
+			for _, c := range columns {
+				qParams.Fields = append(qParams.Fields, c.Name)
+			}
+		}
+
+		size := qParams.Limit
+		if size == 0 {
+			size = pageSize.Default
+		}
+		if size > pageSize.Max {
+			size = pageSize.Max
+		}
+
+		// One extra row tells whether there is a page beyond this one.
+		qParams.Limit = size + 1
+
+		persons, err := personGetter.GetPerson(r.Context(), qParams)
+		if err != nil {
+			log.Error("failed to get persons", sl.Err(err))
+			resp.LegacyStatus(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get persons"))
+			return
+		}
+
+		total, err := personGetter.CountPerson(r.Context(), qParams)
+		if err != nil {
+			log.Error("failed to count persons", sl.Err(err))
+			resp.LegacyStatus(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get persons"))
+			return
+		}
+
+		backward := qParams.Before != nil
+		hasMore := uint(len(persons)) > size
+		if hasMore {
+			if backward {
+				persons = persons[1:]
+			} else {
+				persons = persons[:size]
+			}
+		}
+
+		hasNext, hasPrev := hasMore, qParams.After != nil || qParams.Offset > 0
+		if backward {
+			hasNext, hasPrev = true, hasMore
+		}
+
+		response := Response{
+			Response: resp.OK(),
+			Items:    persons,
+			Total:    total,
+		}
+
+		var links []string
+		if len(persons) > 0 {
+			if hasNext {
+				response.NextCursor = queryparam.NewCursor(qParams.Sort, persons[len(persons)-1]).Encode()
+				links = append(links, link(r, routing.AfterParam, response.NextCursor, size, "next"))
+			}
+			if hasPrev {
+				response.PrevCursor = queryparam.NewCursor(qParams.Sort, persons[0]).Encode()
+				links = append(links, link(r, routing.BeforeParam, response.PrevCursor, size, "prev"))
+			}
+		}
+		if len(links) > 0 {
+			w.Header().Add("Link", strings.Join(links, ", "))
+		}
+
+		rbac.RedactPeople(auth.RoleOf(r.Context()), persons)
+
+		if fields != "" {
+			response.Items = export.Records(persons, columns)
+		}
+
+		// Version 1 has always answered with a bare array; the cursors are in
+		// the Link header and the total in its own.
+		if version.FromContext(r.Context()) == version.V1 && content.FromContext(r.Context()) == content.JSON {
+			w.Header().Set(TotalCountHeader, strconv.Itoa(total))
+			render.JSON(w, r, response.Items)
+			return
+		}
+
+		content.Render(w, r, content.Body{
+			Value:   response,
+			Root:    "response",
+			People:  persons,
+			Columns: columns,
+		})
+	}
+}
+
+// link formats an RFC 8288 link to the page on the other side of the cursor,
+// keeping the filters and sort of the current request.
+func link(r *http.Request, param, cursor string, size uint, rel string) string {
+	q := r.URL.Query()
+	q.Del(routing.AfterParam)
+	q.Del(routing.BeforeParam)
+	q.Del(routing.OffsetParam)
+	q.Del(routing.LimitParam)
+	q.Set(param, cursor)
+	q.Set(routing.SizeParam, fmt.Sprint(size))
+
+	u := *r.URL
+	u.RawQuery = q.Encode()
+
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+}
@@ -0,0 +1,68 @@
+package get
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/api/version"
+	queryparam "people-service/internal/lib/query-param"
+)
+
+type stubGetter []models.Person
+
+func (s stubGetter) GetPerson(ctx context.Context, params queryparam.Params) ([]models.Person, error) {
+	return s, nil
+}
+
+func (s stubGetter) CountPerson(ctx context.Context, params queryparam.Params) (int, error) {
+	return len(s), nil
+}
+
+func TestResponseShapes(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := New(log, stubGetter{{Id: 1, Name: "Anna", Surname: "Ivanova"}}, PageSize{Default: 20, Max: 100})
+
+	serve := func(v int) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/person", nil)
+		r = r.WithContext(version.WithVersion(r.Context(), v))
+		rec := httptest.NewRecorder()
+		handler(rec, r)
+		return rec
+	}
+
+	t.Run("version 1 is a bare array", func(t *testing.T) {
+		rec := serve(version.V1)
+
+		var people []map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &people); err != nil {
+			t.Fatalf("body %s: %v", rec.Body, err)
+		}
+		if len(people) != 1 || people[0]["Id"] != float64(1) || people[0]["Name"] != "Anna" {
+			t.Errorf("got %s, want one person with the original keys", rec.Body)
+		}
+		if got := rec.Header().Get(TotalCountHeader); got != "1" {
+			t.Errorf("got %s %q, want 1", TotalCountHeader, got)
+		}
+	})
+
+	t.Run("version 2 is a page", func(t *testing.T) {
+		rec := serve(version.V2)
+
+		var page struct {
+			Items []map[string]interface{} `json:"items"`
+			Total int                      `json:"total"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("body %s: %v", rec.Body, err)
+		}
+		if len(page.Items) != 1 || page.Total != 1 {
+			t.Errorf("got %s, want a page of one person", rec.Body)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package getbyid
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/api/content"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/export"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/rbac"
+	"people-service/internal/lib/routing"
+	"people-service/internal/storage"
+)
+
+type PersonGetter interface {
+	GetPersonById(ctx context.Context, id int) (models.Person, error)
+}
+
+func New(log *slog.Logger, personGetter PersonGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.getbyid.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		idParam := chi.URLParam(r, routing.PersonIdParam)
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Info("error while parsing person id", slog.String("id", idParam))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid person id"))
+			return
+		}
+
+		fields := r.URL.Query().Get(routing.FieldsParam)
+		columns, err := export.Columns(fields)
+		if err != nil {
+			log.Info("invalid fields", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		person, err := personGetter.GetPersonById(r.Context(), id)
+		if errors.Is(err, storage.ErrPersonNotFound) {
+			log.Info("person not found", slog.Int("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("person not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get person", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get person"))
+			return
+		}
+
+		rbac.Redact(auth.RoleOf(r.Context()), &person)
+
+		var value interface{} = person
+		if fields != "" {
+			value = export.Record(person, columns)
+		}
+
+		content.Render(w, r, content.Body{
+			Value:   value,
+			Root:    "person",
+			People:  []models.Person{person},
+			Columns: columns,
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package importcsv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/csvimport"
+	"people-service/internal/lib/logger/sl"
+)
+
+const maxUploadSize = 32 << 20
+
+// Form fields of the upload.
+const (
+	fileField    = "file"
+	mappingField = "mapping"
+	enrichField  = "enrich"
+)
+
+type Response struct {
+	resp.Response
+	Total     int    `json:"total"`
+	Imported  int    `json:"imported"`
+	Rejected  int    `json:"rejected"`
+	Ids       []int  `json:"ids,omitempty"`
+	ReportUrl string `json:"report_url,omitempty"`
+}
+
+type Importer interface {
+	Import(ctx context.Context, r io.Reader, mapping csvimport.Mapping, enrich bool) (csvimport.Summary, error)
+}
+
+type ReportSaver interface {
+	Put(data []byte) (string, error)
+}
+
+// New imports people from a multipart CSV upload. The optional mapping field
+// maps CSV columns to person fields, e.g. "First Name:name,Last Name:surname",
+// and enrich=true looks missing attributes up upstream.
+func New(log *slog.Logger, importer Importer, reportSaver ReportSaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.importcsv.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+		file, _, err := r.FormFile(fileField)
+		if err != nil {
+			log.Info("failed to read uploaded file", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(fmt.Sprintf("multipart form field %s with a CSV file is required", fileField)))
+			return
+		}
+		defer file.Close()
+
+		var mapping csvimport.Mapping
+		if v := r.FormValue(mappingField); v != "" {
+			mapping, err = csvimport.ParseMapping(v)
+			if err != nil {
+				log.Info("invalid mapping", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error(err.Error()))
+				return
+			}
+		}
+
+		enrich, _ := strconv.ParseBool(r.FormValue(enrichField))
+
+		summary, err := importer.Import(r.Context(), file, mapping, enrich)
+		if err != nil {
+			log.Error("failed to import people", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		response := Response{
+			Response: resp.OK(),
+			Total:    summary.Total,
+			Imported: len(summary.Ids),
+			Rejected: len(summary.Rejected),
+			Ids:      summary.Ids,
+		}
+
+		if len(summary.Rejected) > 0 {
+			var report bytes.Buffer
+			if err := csvimport.WriteReport(&report, summary); err != nil {
+				log.Error("failed to write import report", sl.Err(err))
+			} else if id, err := reportSaver.Put(report.Bytes()); err != nil {
+				log.Error("failed to save import report", sl.Err(err))
+			} else {
+				response.ReportUrl = fmt.Sprintf("/person/import/reports/%s", id)
+			}
+		}
+
+		log.Info("people imported",
+			slog.Int("total", response.Total),
+			slog.Int("imported", response.Imported),
+			slog.Int("rejected", response.Rejected),
+		)
+
+		render.JSON(w, r, response)
+	}
+}
@@ -0,0 +1,46 @@
+package importreport
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/routing"
+)
+
+type ReportGetter interface {
+	Get(id string) ([]byte, bool)
+}
+
+// New serves the CSV error report of an import.
+func New(log *slog.Logger, reportGetter ReportGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.importreport.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		id := chi.URLParam(r, routing.ReportIdParam)
+
+		report, ok := reportGetter.Get(id)
+		if !ok {
+			log.Info("import report not found", slog.String("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("import report not found"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="import-errors-%s.csv"`, id))
+		w.Write(report)
+	}
+}
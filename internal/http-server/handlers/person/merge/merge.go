@@ -0,0 +1,197 @@
+package merge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/validation"
+	"people-service/internal/storage"
+)
+
+// Sides a merged field can be taken from.
+const (
+	Target = "target"
+	Source = "source"
+)
+
+type Request struct {
+	TargetId int        `json:"target_id" validate:"required"`
+	SourceId int        `json:"source_id" validate:"required,nefield=TargetId"`
+	Resolve  Resolution `json:"resolve,omitempty"`
+}
+
+// Resolution tells which side each field is taken from. Fields left empty
+// keep the target value unless it is empty.
+type Resolution struct {
+	Name        string `json:"name,omitempty" validate:"omitempty,oneof=target source"`
+	Surname     string `json:"surname,omitempty" validate:"omitempty,oneof=target source"`
+	Patronymic  string `json:"patronymic,omitempty" validate:"omitempty,oneof=target source"`
+	Age         string `json:"age,omitempty" validate:"omitempty,oneof=target source"`
+	Gender      string `json:"gender,omitempty" validate:"omitempty,oneof=target source"`
+	Nationality string `json:"nationality,omitempty" validate:"omitempty,oneof=target source"`
+}
+
+type Response struct {
+	resp.Response
+	Person *models.Person `json:"person,omitempty"`
+	Id     int            `json:"id,omitempty"`
+}
+
+type PersonMerger interface {
+	GetPersonById(ctx context.Context, id int) (models.Person, error)
+	MergePeople(ctx context.Context, targetId, sourceId int, merged models.Person) error
+}
+
+func New(log *slog.Logger, personMerger PersonMerger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.merge.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("empty request"))
+			return
+		}
+
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("request", req))
+
+		if err := validation.Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		target, err := personMerger.GetPersonById(r.Context(), req.TargetId)
+		if err != nil {
+			failLookup(w, r, log, err, req.TargetId)
+			return
+		}
+		source, err := personMerger.GetPersonById(r.Context(), req.SourceId)
+		if err != nil {
+			failLookup(w, r, log, err, req.SourceId)
+			return
+		}
+
+		merged := Merge(target, source, req.Resolve)
+
+		err = personMerger.MergePeople(r.Context(), req.TargetId, req.SourceId, merged)
+		var existsErr *storage.ExistsError
+		if errors.As(err, &existsErr) {
+			log.Info("merged person conflicts with existing one", slog.Int("conflicting_id", existsErr.Id))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, Response{
+				Response: resp.Error("person already exists"),
+				Id:       existsErr.Id,
+			})
+			return
+		}
+		if errors.Is(err, storage.ErrPersonNotFound) {
+			log.Info("person to merge not found", sl.Err(err))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("person not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to merge people", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to merge people"))
+			return
+		}
+
+		log.Info("people merged", slog.Int("target_id", req.TargetId), slog.Int("source_id", req.SourceId))
+
+		merged.Id = req.TargetId
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Person:   &merged,
+		})
+	}
+}
+
+// Merge combines two people field by field according to the resolution.
+func Merge(target, source models.Person, resolve Resolution) models.Person {
+	merged := target
+
+	merged.Name = pick(resolve.Name, target.Name, source.Name)
+	merged.Surname = pick(resolve.Surname, target.Surname, source.Surname)
+	merged.Patronymic = pick(resolve.Patronymic, target.Patronymic, source.Patronymic)
+
+	if pickSource(resolve.Age, target.Age == 0, source.Age == 0) {
+		merged.Age, merged.AgeSource = source.Age, source.AgeSource
+	}
+	if pickSource(resolve.Gender, target.Gender == "", source.Gender == "") {
+		merged.Gender, merged.GenderSource = source.Gender, source.GenderSource
+	}
+	if pickSource(resolve.Nationality, target.Nationality == "", source.Nationality == "") {
+		merged.Nationality, merged.NationalitySource = source.Nationality, source.NationalitySource
+	}
+
+	if source.EnrichedAt != nil && (target.EnrichedAt == nil || source.EnrichedAt.After(*target.EnrichedAt)) {
+		merged.EnrichedAt = source.EnrichedAt
+	}
+
+	return merged
+}
+
+func pick(side, target, source string) string {
+	if pickSource(side, target == "", source == "") {
+		return source
+	}
+	return target
+}
+
+func pickSource(side string, targetEmpty, sourceEmpty bool) bool {
+	switch side {
+	case Source:
+		return true
+	case Target:
+		return false
+	default:
+		return targetEmpty && !sourceEmpty
+	}
+}
+
+func failLookup(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, id int) {
+	if errors.Is(err, storage.ErrPersonNotFound) {
+		log.Info("person to merge not found", slog.Int("id", id))
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, Response{
+			Response: resp.Error("person not found"),
+			Id:       id,
+		})
+		return
+	}
+
+	log.Error("failed to get person", sl.Err(err))
+	render.Status(r, http.StatusInternalServerError)
+	render.JSON(w, r, resp.Error("failed to get person"))
+}
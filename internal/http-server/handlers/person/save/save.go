@@ -1,143 +1,195 @@
-package save
-
-import (
-	"errors"
-	"fmt"
-	"io"
-	"log/slog"
-	"net/http"
-	"people-service/internal/domain/models"
-	resp "people-service/internal/lib/api/response"
-	"people-service/internal/storage"
-
-	"github.com/go-chi/chi/middleware"
-	"github.com/go-chi/render"
-	"github.com/go-playground/validator/v10"
-)
-
-type Request struct {
-	Name       string `json:"name" validate:"required"`
-	Surname    string `json:"surname" validate:"required"`
-	Patronymic string `json:"patronymic,omitempty"`
-}
-
-type Response struct {
-	resp.Response
-	Id int `json:"id,omitempty"`
-}
-
-type PersonSaver interface {
-	SavePerson(people models.Person) (id int, err error)
-}
-
-type AgeGetter interface {
-	GetAge(name string) (age int, err error)
-}
-
-type GenderGetter interface {
-	GetGender(name string) (gender string, err error)
-}
-
-type NationalityGetter interface {
-	GetNationality(name string) (nationality string, err error)
-}
-
-func New(log *slog.Logger,
-	personSaver PersonSaver,
-	ageGetter AgeGetter,
-	genderGetter GenderGetter,
-	nationalityGetter NationalityGetter,
-) http.HandlerFunc {
-
-	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.person.save.New"
-
-		log := log.With(
-			slog.String("op", op),
-			slog.String("request_id", middleware.GetReqID(r.Context())),
-		)
-
-		log.Debug("entered to save person handler")
-
-		var req Request
-
-		err := render.DecodeJSON(r.Body, &req)
-
-		if errors.Is(err, io.EOF) {
-			log.Error("request body is empty")
-			render.JSON(w, r, resp.Error("empty request"))
-			return
-		}
-
-		if err != nil {
-			log.Error("failed to decode request body", err)
-			render.JSON(w, r, resp.Error("failed to decode request"))
-			return
-		}
-
-		log.Info("request body decoded", slog.Any("request", req))
-
-		if err := validator.New().Struct(req); err != nil {
-			validateErr := err.(validator.ValidationErrors)
-			log.Error("invalid request", err)
-			render.JSON(w, r, resp.ValidationError(validateErr))
-			return
-		}
-
-		log.Debug("req is OK")
-
-		person := models.Person{Name: req.Name, Surname: req.Surname}
-		if req.Patronymic != "" {
-			person.Patronymic = req.Patronymic
-		}
-
-		age, err := ageGetter.GetAge(person.Name)
-		if err != nil {
-			log.Error("failed to get age", err)
-		} else {
-			person.Age = age
-		}
-
-		log.Debug(fmt.Sprintf("age is: %s", age))
-
-		gender, err := genderGetter.GetGender(person.Name)
-		if err != nil {
-			log.Error("failed to get gender", err)
-		} else {
-			person.Gender = gender
-		}
-		log.Debug(fmt.Sprintf("gender is: %s", gender))
-
-		nationality, err := nationalityGetter.GetNationality(person.Name)
-		if err != nil {
-			log.Error("failed to get nationality", err)
-		} else {
-			person.Nationality = nationality
-		}
-		log.Debug(fmt.Sprintf("nationality is: %s", nationality))
-
-		id, err := personSaver.SavePerson(person)
-
-		if errors.Is(err, storage.ErrPersonExists) {
-			log.Info("person already exists", slog.String("name", person.Name), slog.String("surname", person.Surname))
-			render.JSON(w, r, resp.Error("person already exists"))
-			return
-		}
-		if err != nil {
-			log.Error("failed to add person", err)
-			render.JSON(w, r, resp.Error("failed to add person"))
-			return
-		}
-
-		log.Info("person added", slog.Int("id", id))
-
-		responseOK(w, r, id)
-	}
-}
-
-func responseOK(w http.ResponseWriter, r *http.Request, id int) {
-	render.JSON(w, r, Response{
-		Response: resp.OK(),
-		Id:       id,
-	})
-}
+package save
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/api/version"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/names"
+	"people-service/internal/lib/translit"
+	"people-service/internal/lib/validation"
+	"people-service/internal/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Request struct {
+	Name       string `json:"name" validate:"required,person_name"`
+	Surname    string `json:"surname" validate:"required,person_name"`
+	Patronymic string `json:"patronymic,omitempty" validate:"omitempty,person_name"`
+}
+
+// Normalize brings the names to their canonical spelling.
+func (req *Request) Normalize() {
+	req.Name = names.Normalize(req.Name)
+	req.Surname = names.Normalize(req.Surname)
+	req.Patronymic = names.Normalize(req.Patronymic)
+}
+
+type Response struct {
+	resp.Response
+	Id int `json:"id,omitempty"`
+}
+
+type PersonSaver interface {
+	SavePerson(ctx context.Context, people models.Person) (id int, err error)
+}
+
+type AgeGetter interface {
+	GetAge(ctx context.Context, name string) (age int, err error)
+}
+
+type GenderGetter interface {
+	GetGender(ctx context.Context, name string) (gender string, err error)
+}
+
+type NationalityGetter interface {
+	GetNationality(ctx context.Context, name string) (nationality string, err error)
+}
+
+func New(log *slog.Logger,
+	personSaver PersonSaver,
+	ageGetter AgeGetter,
+	genderGetter GenderGetter,
+	nationalityGetter NationalityGetter,
+) http.HandlerFunc {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.save.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		log.Debug("entered to save person handler")
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			resp.LegacyStatus(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("empty request"))
+			return
+		}
+
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			resp.LegacyStatus(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("request", req))
+
+		req.Normalize()
+
+		if err := validation.Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			resp.LegacyStatus(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		log.Debug("req is OK")
+
+		person := models.Person{Name: req.Name, Surname: req.Surname}
+		if req.Patronymic != "" {
+			person.Patronymic = req.Patronymic
+		}
+
+		// Upstream services know names in Latin script.
+		latinName := translit.Latin(person.Name)
+
+		age, err := ageGetter.GetAge(r.Context(), latinName)
+		if err != nil {
+			log.Error("failed to get age", sl.Err(err))
+		} else {
+			person.Age = age
+			person.AgeSource = models.SourceAgify
+		}
+
+		log.Debug(fmt.Sprintf("age is: %d", age))
+
+		gender, err := genderGetter.GetGender(r.Context(), latinName)
+		if err != nil {
+			log.Error("failed to get gender", sl.Err(err))
+		} else {
+			person.Gender = gender
+			person.GenderSource = models.SourceGenderize
+		}
+		log.Debug(fmt.Sprintf("gender is: %s", gender))
+
+		nationality, err := nationalityGetter.GetNationality(r.Context(), latinName)
+		if err != nil {
+			log.Error("failed to get nationality", sl.Err(err))
+		} else {
+			person.Nationality = nationality
+			person.NationalitySource = models.SourceNationalize
+		}
+		log.Debug(fmt.Sprintf("nationality is: %s", nationality))
+
+		if person.AgeSource != "" || person.GenderSource != "" || person.NationalitySource != "" {
+			enrichedAt := time.Now()
+			person.EnrichedAt = &enrichedAt
+		}
+
+		id, err := personSaver.SavePerson(r.Context(), person)
+
+		if errors.Is(err, storage.ErrPersonExists) {
+			log.Info("person already exists", slog.String("name", person.Name), slog.String("surname", person.Surname))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, Response{
+				Response: resp.Error("person already exists"),
+				Id:       conflictingId(err),
+			})
+			return
+		}
+		if err != nil {
+			log.Error("failed to add person", sl.Err(err))
+			resp.LegacyStatus(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to add person"))
+			return
+		}
+
+		log.Info("person added", slog.Int("id", id))
+
+		responseOK(w, r, id)
+	}
+}
+
+func conflictingId(err error) int {
+	var existsErr *storage.ExistsError
+	if errors.As(err, &existsErr) {
+		return existsErr.Id
+	}
+	return 0
+}
+
+// responseOK answers version 2 and later with 201 and the location of the
+// person; version 1 answers 200.
+func responseOK(w http.ResponseWriter, r *http.Request, id int) {
+	if version.FromContext(r.Context()) >= version.V2 {
+		w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+strconv.Itoa(id))
+		render.Status(r, http.StatusCreated)
+	}
+	render.JSON(w, r, Response{
+		Response: resp.OK(),
+		Id:       id,
+	})
+}
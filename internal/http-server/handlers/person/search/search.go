@@ -0,0 +1,94 @@
+package search
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/api/content"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/export"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/rbac"
+	"people-service/internal/lib/routing"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type Response struct {
+	resp.Response
+	Results []models.SearchResult `json:"results" xml:"results>result"`
+}
+
+type PersonSearcher interface {
+	SearchPeople(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
+}
+
+func New(log *slog.Logger, personSearcher PersonSearcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.search.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		query := strings.TrimSpace(r.URL.Query().Get(routing.QueryParam))
+		if query == "" {
+			log.Info("empty search query")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("query parameter q is required"))
+			return
+		}
+
+		limit := defaultLimit
+		if v := r.URL.Query().Get(routing.LimitParam); v != "" {
+			var err error
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 || limit > maxLimit {
+				log.Info("invalid limit", slog.String("limit", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("limit must be an integer in [1, 100]"))
+				return
+			}
+		}
+
+		results, err := personSearcher.SearchPeople(r.Context(), query, limit)
+		if err != nil {
+			log.Error("failed to search people", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to search people"))
+			return
+		}
+
+		log.Info("people found", slog.String("query", query), slog.Int("count", len(results)))
+
+		role := auth.RoleOf(r.Context())
+		people := make([]models.Person, len(results))
+		for i := range results {
+			rbac.Redact(role, &results[i].Person)
+			people[i] = results[i].Person
+		}
+
+		content.Render(w, r, content.Body{
+			Value: Response{
+				Response: resp.OK(),
+				Results:  results,
+			},
+			Root:    "response",
+			People:  people,
+			Columns: export.AllColumns(),
+		})
+	}
+}
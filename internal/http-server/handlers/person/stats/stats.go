@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/logger/sl"
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/lib/routing"
+)
+
+const (
+	defaultBucketWidth = 10
+	maxBucketWidth     = 150
+)
+
+type Response struct {
+	resp.Response
+	models.Stats
+}
+
+type StatsGetter interface {
+	GetStats(ctx context.Context, params queryparam.Params, bucketWidth int) (models.Stats, error)
+}
+
+func New(log *slog.Logger, statsGetter StatsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.stats.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		qParams, err := queryparam.Parse(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query parameters", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		bucketWidth := defaultBucketWidth
+		if v := r.URL.Query().Get(routing.BucketParam); v != "" {
+			bucketWidth, err = strconv.Atoi(v)
+			if err != nil || bucketWidth < 1 || bucketWidth > maxBucketWidth {
+				log.Info("invalid bucket width", slog.String("bucket", v))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("bucket must be an integer in [1, 150]"))
+				return
+			}
+		}
+
+		stats, err := statsGetter.GetStats(r.Context(), qParams, bucketWidth)
+		if err != nil {
+			log.Error("failed to get stats", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get stats"))
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Stats:    stats,
+		})
+	}
+}
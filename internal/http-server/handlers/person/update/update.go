@@ -1,93 +1,154 @@
-package update
-
-import (
-	"errors"
-	"io"
-	"log/slog"
-	"net/http"
-	"strconv"
-
-	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
-	"github.com/go-chi/render"
-
-	"people-service/internal/domain/models"
-	resp "people-service/internal/lib/api/response"
-	"people-service/internal/lib/routing"
-)
-
-type Request struct {
-	Name        string `json:"name,omitempty"`
-	Surname     string `json:"surname,omitempty"`
-	Patronymic  string `json:"patronymic,omitempty"`
-	Age         int    `json:"age,omitempty"`
-	Gender      string `json:"gender,omitempty"`
-	Nationality string `json:"nationality,omitempty"`
-}
-
-type Response struct {
-	resp.Response
-}
-
-type PersonUpdater interface {
-	UpdatePerson(id int, person models.Person) error
-}
-
-func New(log *slog.Logger, personUpdater PersonUpdater) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.person.update.New"
-
-		log := log.With(
-			slog.String("op", op),
-			slog.String("request_id", middleware.GetReqID(r.Context())),
-		)
-
-		idParam := chi.URLParam(r, routing.PersonIdParam)
-		id, err := strconv.Atoi(idParam)
-		if err != nil {
-			log.Info("error while parsing person id", slog.String("id", idParam))
-			render.JSON(w, r, resp.Error("error while updating person"))
-			return
-		}
-
-		var req Request
-
-		err = render.DecodeJSON(r.Body, &req)
-
-		if errors.Is(err, io.EOF) {
-			log.Error("request body is empty")
-			render.JSON(w, r, resp.Error("empty request"))
-			return
-		}
-
-		if err != nil {
-			log.Error("failed to decode request body", err)
-			render.JSON(w, r, resp.Error("failed to decode request"))
-			return
-		}
-
-		log.Info("request body decoded", slog.Any("request", req))
-
-		person := models.Person{
-			Name:        req.Name,
-			Surname:     req.Surname,
-			Patronymic:  req.Patronymic,
-			Age:         req.Age,
-			Gender:      req.Gender,
-			Nationality: req.Nationality,
-		}
-
-		err = personUpdater.UpdatePerson(id, person)
-		if err != nil {
-			log.Info("error while updating person", slog.Int("id", id))
-			render.JSON(w, r, resp.Error("error while updating person"))
-			return
-		}
-
-		log.Info("person updated", slog.Int("id", id))
-
-		render.JSON(w, r, Response{
-			Response: resp.OK(),
-		})
-	}
-}
+package update
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/names"
+	"people-service/internal/lib/rbac"
+	"people-service/internal/lib/routing"
+	"people-service/internal/lib/validation"
+	"people-service/internal/storage"
+)
+
+type Request struct {
+	Name        string `json:"name,omitempty" validate:"omitempty,person_name"`
+	Surname     string `json:"surname,omitempty" validate:"omitempty,person_name"`
+	Patronymic  string `json:"patronymic,omitempty" validate:"omitempty,person_name"`
+	Age         int    `json:"age,omitempty" validate:"person_age"`
+	Gender      string `json:"gender,omitempty" validate:"omitempty,gender"`
+	Nationality string `json:"nationality,omitempty" validate:"omitempty,person_nationality"`
+}
+
+type Response struct {
+	resp.Response
+	Id int `json:"id,omitempty"`
+}
+
+type PersonUpdater interface {
+	GetPersonById(ctx context.Context, id int) (models.Person, error)
+	UpdatePerson(ctx context.Context, id int, person models.Person) error
+}
+
+func New(log *slog.Logger, personUpdater PersonUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.person.update.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			sl.TraceID(r.Context()),
+		)
+
+		idParam := chi.URLParam(r, routing.PersonIdParam)
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Info("error while parsing person id", slog.String("id", idParam))
+			resp.LegacyStatus(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("error while updating person"))
+			return
+		}
+
+		var req Request
+
+		err = render.DecodeJSON(r.Body, &req)
+
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			resp.LegacyStatus(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("empty request"))
+			return
+		}
+
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			resp.LegacyStatus(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("request", req))
+
+		req.Name = names.Normalize(req.Name)
+		req.Surname = names.Normalize(req.Surname)
+		req.Patronymic = names.Normalize(req.Patronymic)
+
+		if err := validation.Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Info("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validateErr))
+			return
+		}
+
+		person := models.Person{
+			Name:        req.Name,
+			Surname:     req.Surname,
+			Patronymic:  req.Patronymic,
+			Age:         req.Age,
+			Gender:      req.Gender,
+			Nationality: req.Nationality,
+		}
+		if person.Age != 0 {
+			person.AgeSource = models.SourceManual
+		}
+		if person.Gender != "" {
+			person.GenderSource = models.SourceManual
+		}
+		if person.Nationality != "" {
+			person.NationalitySource = models.SourceManual
+		}
+
+		if role := auth.RoleOf(r.Context()); !role.Allows(rbac.OverrideEnriched) {
+			current, err := personUpdater.GetPersonById(r.Context(), id)
+			if err != nil && !errors.Is(err, storage.ErrPersonNotFound) {
+				log.Error("failed to get person", sl.Err(err))
+				resp.LegacyStatus(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("error while updating person"))
+				return
+			}
+			if fields := rbac.Overrides(role, current, &person); len(fields) > 0 {
+				log.Info("enrichment-derived attributes may not be overridden", slog.Any("fields", fields))
+				resp.RenderProblem(w, resp.Forbidden(r, "only the "+string(rbac.OverrideEnriched)+" role can override enrichment-derived attributes", fields...))
+				return
+			}
+		}
+
+		err = personUpdater.UpdatePerson(r.Context(), id, person)
+		var existsErr *storage.ExistsError
+		if errors.As(err, &existsErr) {
+			log.Info("person already exists", slog.Int("id", id), slog.Int("conflicting_id", existsErr.Id))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, Response{
+				Response: resp.Error("person already exists"),
+				Id:       existsErr.Id,
+			})
+			return
+		}
+		if err != nil {
+			log.Info("error while updating person", slog.Int("id", id))
+			resp.LegacyStatus(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("error while updating person"))
+			return
+		}
+
+		log.Info("person updated", slog.Int("id", id))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+		})
+	}
+}
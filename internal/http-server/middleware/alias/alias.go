@@ -0,0 +1,79 @@
+package alias
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/lib/routing"
+	"people-service/internal/storage"
+)
+
+type AliasResolver interface {
+	ResolveAlias(ctx context.Context, id int) (int, error)
+}
+
+// New redirects reads of ids of merged people to the person they were
+// merged into. Other methods get 410, so that a change meant for the merged
+// person is not applied to another one. It learns of merges from storage, so
+// it belongs after the authorization of the route.
+func New(log *slog.Logger, aliasResolver AliasResolver) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/alias"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			idParam := chi.URLParam(r, routing.PersonIdParam)
+			id, err := strconv.Atoi(idParam)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			personId, err := aliasResolver.ResolveAlias(r.Context(), id)
+			if err != nil {
+				if !errors.Is(err, storage.ErrPersonNotFound) {
+					log.Error("failed to resolve person alias", sl.Err(err))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				log.Info("merged person addressed",
+					slog.Int("id", id),
+					slog.String("method", r.Method),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					sl.TraceID(r.Context()),
+				)
+				resp.LegacyStatus(r, http.StatusGone)
+				render.JSON(w, r, resp.Error("person was merged into another one"))
+				return
+			}
+
+			target := *r.URL
+			target.Path = strings.Replace(r.URL.Path, "/"+idParam, "/"+strconv.Itoa(personId), 1)
+
+			log.Info("redirecting merged person",
+				slog.Int("id", id),
+				slog.Int("person_id", personId),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				sl.TraceID(r.Context()),
+			)
+
+			http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
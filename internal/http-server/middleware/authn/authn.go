@@ -0,0 +1,126 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/storage"
+)
+
+// APIKeyHeader carries an API key as an alternative to the Authorization
+// header.
+const APIKeyHeader = "X-API-Key"
+
+type APIKeyFinder interface {
+	FindAPIKey(ctx context.Context, keyHash string) (models.APIKey, error)
+}
+
+type TokenVerifier interface {
+	Verify(token string) (auth.Principal, error)
+}
+
+type Options struct {
+	// Required rejects requests without credentials; otherwise they are
+	// served anonymously with AnonymousRole.
+	Required      bool
+	AnonymousRole auth.Role
+}
+
+// anonymous is the subject of requests served without credentials.
+const anonymous = "anonymous"
+
+// New authenticates requests by an API key, sent in the X-API-Key header or
+// as a bearer token, or by a JWT bearer token, and puts the principal in the
+// request context. Invalid credentials are answered with 401. tokenVerifier
+// may be nil if no JWT keys are configured.
+func New(log *slog.Logger, apiKeyFinder APIKeyFinder, tokenVerifier TokenVerifier, opts Options) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/authn"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := log.With(
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				sl.TraceID(r.Context()),
+			)
+
+			credential := credentials(r)
+			if credential == "" {
+				if opts.Required {
+					unauthorized(w, r, "credentials are required")
+					return
+				}
+				principal := auth.Principal{Subject: anonymous, Method: auth.MethodAnonymous, Role: opts.AnonymousRole}
+				next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+				return
+			}
+
+			var principal auth.Principal
+			if auth.IsAPIKey(credential) {
+				apiKey, err := apiKeyFinder.FindAPIKey(r.Context(), auth.HashAPIKey(credential))
+				if errors.Is(err, storage.ErrAPIKeyNotFound) {
+					log.Info("unknown or revoked api key")
+					unauthorized(w, r, "invalid api key")
+					return
+				}
+				if err != nil {
+					log.Error("failed to find api key", sl.Err(err))
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, resp.Error("failed to authenticate"))
+					return
+				}
+				principal = auth.Principal{
+					Subject: "api-key:" + strconv.Itoa(apiKey.Id),
+					Method:  auth.MethodAPIKey,
+					Role:    auth.Role(apiKey.Role),
+				}
+			} else {
+				if tokenVerifier == nil {
+					log.Info("bearer token given but no JWT keys are configured")
+					unauthorized(w, r, "invalid token")
+					return
+				}
+				var err error
+				principal, err = tokenVerifier.Verify(credential)
+				if err != nil {
+					log.Info("invalid token", sl.Err(err))
+					unauthorized(w, r, "invalid token")
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func credentials(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="people-service"`)
+	render.Status(r, http.StatusUnauthorized)
+	render.JSON(w, r, resp.Error(msg))
+}
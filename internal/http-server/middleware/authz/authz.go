@@ -0,0 +1,41 @@
+package authz
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/logger/sl"
+)
+
+// Require answers requests whose principal lacks the privileges of the role
+// with 403.
+func Require(log *slog.Logger, role auth.Role) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/authz"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := auth.FromContext(r.Context())
+			if !principal.Role.Allows(role) {
+				log.Info("operation not allowed",
+					slog.String("principal", principal.Subject),
+					slog.String("role", string(principal.Role)),
+					slog.String("required_role", string(role)),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					sl.TraceID(r.Context()),
+				)
+				resp.RenderProblem(w, resp.Forbidden(r, "the operation requires the "+string(role)+" role"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,211 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/api/version"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/logger/sl"
+	"people-service/internal/storage"
+)
+
+const (
+	// Header carries the key a client picks for a request and repeats on
+	// its retries.
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks responses replayed from storage.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	MaxKeyLength = 255
+)
+
+// storedHeaders are the response headers replayed along with the body.
+var storedHeaders = []string{"Content-Type", "Location"}
+
+type ResponseStore interface {
+	ReserveIdempotencyKey(ctx context.Context, key, requestHash string, lease time.Duration) (*models.IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, key string, response models.IdempotentResponse, ttl time.Duration) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+// Options tell how long keys are held. TTL is how long a response is
+// replayed. Lease is how long a request in progress holds its key; if the
+// service goes down before the response is stored, the key can be used again
+// once the lease runs out, so it should outlast the longest request.
+type Options struct {
+	TTL   time.Duration
+	Lease time.Duration
+}
+
+// New makes requests carrying an Idempotency-Key header safe to retry: the
+// first response is stored for opts.TTL and replayed to repeats of the
+// request. Reusing a key for a different request is answered with 422.
+// Responses with a 5xx status are not stored, so that the request can be
+// retried.
+func New(log *slog.Logger, responseStore ResponseStore, opts Options) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/idempotency"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(Header)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > MaxKeyLength {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error(Header+" must be at most "+strconv.Itoa(MaxKeyLength)+" characters long"))
+				return
+			}
+
+			log := log.With(
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				sl.TraceID(r.Context()),
+				slog.String("idempotency_key", key),
+			)
+
+			// Keys are picked by clients, so each client has its own. They
+			// are stored as digests, which fit the column whatever the length
+			// of the client key.
+			key = storageKey(auth.ClientKey(r), key)
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				log.Error("failed to read request body", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("failed to read request"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			stored, err := responseStore.ReserveIdempotencyKey(r.Context(), key, requestHash(r, body), opts.Lease)
+			switch {
+			case errors.Is(err, storage.ErrIdempotencyKeyMismatch):
+				log.Info("idempotency key reused for another request")
+				render.Status(r, http.StatusUnprocessableEntity)
+				render.JSON(w, r, resp.Error(Header+" was already used for a different request"))
+				return
+			case errors.Is(err, storage.ErrIdempotencyKeyInUse):
+				log.Info("idempotency key in use")
+				render.Status(r, http.StatusConflict)
+				render.JSON(w, r, resp.Error("a request with this "+Header+" is in progress"))
+				return
+			case err != nil:
+				log.Error("failed to reserve idempotency key", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to process request"))
+				return
+			case stored != nil:
+				log.Info("replaying stored response")
+				replay(w, *stored)
+				return
+			}
+
+			// The outcome is stored even if the client goes away meanwhile,
+			// so that its retry is answered from the store.
+			ctx := context.WithoutCancel(r.Context())
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			saved := false
+			defer func() {
+				if saved {
+					return
+				}
+				if err := responseStore.ReleaseIdempotencyKey(ctx, key); err != nil {
+					log.Error("failed to release idempotency key", sl.Err(err))
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				return
+			}
+
+			response := models.IdempotentResponse{
+				Status: rec.status,
+				Header: make(map[string]string),
+				Body:   rec.body.Bytes(),
+			}
+			for _, h := range storedHeaders {
+				if v := w.Header().Get(h); v != "" {
+					response.Header[h] = v
+				}
+			}
+			if err := responseStore.SaveIdempotentResponse(ctx, key, response, opts.TTL); err != nil {
+				log.Error("failed to save idempotent response", sl.Err(err))
+				return
+			}
+			saved = true
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// storageKey is the key of the client as stored.
+func storageKey(client, key string) string {
+	sum := sha256.Sum256([]byte(client + " " + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestHash identifies a request by its method, URL, API version and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+" "+strconv.Itoa(version.FromContext(r.Context()))+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, response models.IdempotentResponse) {
+	for h, v := range response.Header {
+		w.Header().Set(h, v)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(response.Status)
+	w.Write(response.Body)
+}
+
+// recorder passes the response through while keeping a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
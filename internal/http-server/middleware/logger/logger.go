@@ -1,43 +1,52 @@
-package logger
-
-import (
-	"net/http"
-	"time"
-
-	"github.com/go-chi/chi/v5/middleware"
-	"log/slog"
-)
-
-func New(log *slog.Logger) func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		log := log.With(
-			slog.String("component", "middleware/logger"),
-		)
-
-		log.Info("logger middleware enabled")
-
-		fn := func(w http.ResponseWriter, r *http.Request) {
-			entry := log.With(
-				slog.String("method", r.Method),
-				slog.String("path", r.URL.Path),
-				slog.String("remote_addr", r.RemoteAddr),
-				slog.String("user_agent", r.UserAgent()),
-				slog.String("request_id", middleware.GetReqID(r.Context())),
-			)
-			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
-
-			t1 := time.Now()
-			defer func() {
-				entry.Info("request completed",
-					slog.Int("status", ww.Status()),
-					slog.Int("bytes", ww.BytesWritten()),
-					slog.String("duration", time.Since(t1).String()),
-				)
-			}()
-
-			next.ServeHTTP(ww, r)
-		}
-
-		return http.HandlerFunc(fn)
-	}
-}
\ No newline at end of file
+package logger
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"log/slog"
+
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/logger/sl"
+)
+
+func New(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/logger"),
+		)
+
+		log.Info("logger middleware enabled")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			entry := log.With(
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("user_agent", r.UserAgent()),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				sl.TraceID(r.Context()),
+			)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ctx, principal := auth.Observe(r.Context())
+
+			t1 := time.Now()
+			defer func() {
+				attrs := []any{
+					slog.Int("status", ww.Status()),
+					slog.Int("bytes", ww.BytesWritten()),
+					slog.String("duration", time.Since(t1).String()),
+				}
+				if p, ok := principal(); ok {
+					attrs = append(attrs, slog.String("principal", p.Subject))
+				}
+				entry.Info("request completed", attrs...)
+			}()
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
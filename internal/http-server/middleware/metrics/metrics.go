@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+
+	"people-service/internal/lib/metrics"
+	"people-service/internal/lib/routing"
+)
+
+// unmatched labels requests no route matched.
+const unmatched = "unmatched"
+
+// New counts requests and measures their latency by method, chi route
+// pattern and status. It must run before the router matches the request
+// so that the pattern is complete once the handler returns.
+func New(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		start := time.Now()
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := routing.Pattern(r)
+		if route == "" {
+			route = unmatched
+		}
+		labels := []string{r.Method, route, strconv.Itoa(status)}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"golang.org/x/time/rate"
+
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/auth"
+	"people-service/internal/lib/logger/sl"
+)
+
+// idleTimeout is how long the bucket of a client that makes no requests is
+// kept.
+const idleTimeout = 10 * time.Minute
+
+// Limit lets a client make Requests requests per Per, all at once or spread
+// out. Zero Requests means no limit.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type limiter struct {
+	limit     Limit
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New limits the requests of each client to the routes it guards with a
+// token bucket. Clients are told apart by their principal or, when they are
+// anonymous, by their IP address. Requests over the limit are answered with
+// 429 and Retry-After; every response carries RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers. name tells the limits
+// apart in logs. Routes guarded by the same middleware share the buckets.
+func New(log *slog.Logger, name string, limit Limit) func(next http.Handler) http.Handler {
+	log = log.With(
+		slog.String("component", "middleware/ratelimit"),
+		slog.String("limit", name),
+	)
+
+	l := &limiter{limit: limit, buckets: make(map[string]*bucket)}
+
+	return func(next http.Handler) http.Handler {
+		if limit.Requests <= 0 {
+			return next
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			now := time.Now()
+			key := auth.ClientKey(r)
+			lim := l.bucket(key, now)
+
+			reservation := lim.ReserveN(now, 1)
+			delay := reservation.DelayFrom(now)
+			if delay > 0 {
+				reservation.CancelAt(now)
+			}
+
+			tokens := lim.TokensAt(now)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(int(math.Max(0, math.Floor(tokens)))))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(l.untilFull(tokens))))
+
+			if delay > 0 {
+				log.Info("rate limit exceeded",
+					slog.String("client", key),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					sl.TraceID(r.Context()),
+				)
+				w.Header().Set("Retry-After", strconv.Itoa(seconds(delay)))
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, resp.Error("rate limit exceeded, retry later"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Select guards each request with the limiter that pick names for it, so
+// that routes with different limits can be limited by their group, before
+// they are routed. Requests pick no limiter for are not limited.
+func Select(pick func(r *http.Request) string, limiters map[string]func(next http.Handler) http.Handler) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		handlers := make(map[string]http.Handler, len(limiters))
+		for name, limiter := range limiters {
+			handlers[name] = limiter(next)
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if h, ok := handlers[pick(r)]; ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// bucket returns the token bucket of the client, dropping buckets of clients
+// idle for a while.
+func (l *limiter) bucket(key string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleTimeout {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > idleTimeout {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		every := rate.Every(l.limit.Per / time.Duration(l.limit.Requests))
+		b = &bucket{limiter: rate.NewLimiter(every, l.limit.Requests)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	return b.limiter
+}
+
+// untilFull returns how long the bucket takes to refill from tokens.
+func (l *limiter) untilFull(tokens float64) time.Duration {
+	missing := float64(l.limit.Requests) - tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing * float64(l.limit.Per) / float64(l.limit.Requests))
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"people-service/internal/lib/auth"
+)
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func request(subject, remoteAddr string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/person", nil)
+	r.RemoteAddr = remoteAddr
+	if subject != "" {
+		r = r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{Subject: subject, Method: auth.MethodAPIKey}))
+	}
+	return r
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name     string
+		requests []*http.Request
+		want     []int
+	}{
+		{
+			"within the limit",
+			[]*http.Request{request("key:1", "10.0.0.1:1"), request("key:1", "10.0.0.1:1")},
+			[]int{http.StatusOK, http.StatusOK},
+		},
+		{
+			"over the limit",
+			[]*http.Request{request("key:1", "10.0.0.1:1"), request("key:1", "10.0.0.1:1"), request("key:1", "10.0.0.2:1")},
+			[]int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			"principals on one address",
+			[]*http.Request{request("key:1", "10.0.0.1:1"), request("key:1", "10.0.0.1:1"), request("key:2", "10.0.0.1:1")},
+			[]int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			"anonymous by address",
+			[]*http.Request{request("", "10.0.0.1:1"), request("", "10.0.0.1:2"), request("", "10.0.0.1:3"), request("", "10.0.0.2:1")},
+			[]int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(log, "test", Limit{Requests: 2, Per: time.Hour})(ok)
+			for i, r := range tt.requests {
+				if got := serve(h, r).Code; got != tt.want[i] {
+					t.Errorf("request %d: got status %d, want %d", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := New(log, "test", Limit{Requests: 2, Per: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := serve(h, request("key:1", "10.0.0.1:1"))
+	if got := rec.Header().Get("RateLimit-Limit"); got != "2" {
+		t.Errorf("got RateLimit-Limit %q, want 2", got)
+	}
+	if got := rec.Header().Get("RateLimit-Remaining"); got != "1" {
+		t.Errorf("got RateLimit-Remaining %q, want 1", got)
+	}
+	if got := rec.Header().Get("RateLimit-Reset"); got != "30" {
+		t.Errorf("got RateLimit-Reset %q, want 30", got)
+	}
+
+	serve(h, request("key:1", "10.0.0.1:1"))
+	rec = serve(h, request("key:1", "10.0.0.1:1"))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("got Retry-After %q, want 30", got)
+	}
+}
+
+func TestUnlimited(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := New(log, "test", Limit{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i := 0; i < 10; i++ {
+		if rec := serve(h, request("", "10.0.0.1:1")); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("request %d: got status %d and headers %v, want it passed through", i, rec.Code, rec.Header())
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := Select(func(r *http.Request) string { return r.Method }, map[string]func(http.Handler) http.Handler{
+		http.MethodPost: New(log, "write", Limit{Requests: 1, Per: time.Hour}),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	post := func() *http.Request {
+		r := request("key:1", "10.0.0.1:1")
+		r.Method = http.MethodPost
+		return r
+	}
+
+	if got := serve(h, post()).Code; got != http.StatusOK {
+		t.Fatalf("first POST: got status %d", got)
+	}
+	if got := serve(h, post()).Code; got != http.StatusTooManyRequests {
+		t.Errorf("second POST: got status %d, want %d", got, http.StatusTooManyRequests)
+	}
+	if got := serve(h, request("key:1", "10.0.0.1:1")).Code; got != http.StatusOK {
+		t.Errorf("GET: got status %d, want it not limited", got)
+	}
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"people-service/internal/lib/routing"
+)
+
+// untraced are the routes probed by the orchestrator and the metrics
+// scraper, too frequent and uneventful to trace.
+var untraced = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// New starts a span for each request, continuing the trace of the caller
+// if it sent a traceparent header. Spans are named by method and chi route
+// pattern. It must run before the router matches the request.
+func New(next http.Handler) http.Handler {
+	named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		span := trace.SpanFromContext(r.Context())
+		// Query parameters may filter by name, so only the path is kept.
+		span.SetAttributes(attribute.String("http.target", r.URL.Path))
+		if route := routing.Pattern(r); route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(attribute.String("http.route", route))
+		}
+	})
+
+	return otelhttp.NewHandler(named, "http.server",
+		otelhttp.WithFilter(func(r *http.Request) bool { return !untraced[r.URL.Path] }),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string { return r.Method }),
+	)
+}
@@ -0,0 +1,241 @@
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"people-service/internal/http-server/openapi"
+	"people-service/internal/lib/api/content"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/logger/sl"
+)
+
+type Options struct {
+	// Responses also checks JSON responses against the document and replaces
+	// those that do not match with 500. Responses are buffered to do so, so
+	// this is meant for tests rather than production.
+	Responses bool
+
+	// MaxBodyBytes caps request bodies, which are read into memory to be
+	// validated. Larger bodies are answered with 413. Zero means no cap.
+	MaxBodyBytes int64
+}
+
+// streamed are media types of bodies that handlers read as streams, with caps
+// of their own. Their bodies are not validated, which would read them whole.
+var streamed = []string{content.NDJSON, "multipart/form-data"}
+
+// New checks requests to the routes described by the document before they
+// reach handlers and answers those that do not match it with 400 and the list
+// of offending parameters and body fields. Other routes are passed through.
+func New(log *slog.Logger, doc *openapi.Document, opts Options) (func(next http.Handler) http.Handler, error) {
+	const op = "middleware.validate.New"
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	spec, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/validate"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			excludeBody := isStreamed(r)
+			if opts.MaxBodyBytes > 0 && !excludeBody {
+				r.Body = http.MaxBytesReader(w, r.Body, opts.MaxBodyBytes)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					MultiError:         true,
+					ExcludeRequestBody: excludeBody,
+					// Credentials are checked by the authn middleware.
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				log.Info("invalid request",
+					sl.Err(err),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					sl.TraceID(r.Context()),
+				)
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					render.Status(r, http.StatusRequestEntityTooLarge)
+					render.JSON(w, r, resp.Error(fmt.Sprintf("request body exceeds %d bytes", maxErr.Limit)))
+					return
+				}
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.InvalidRequest(fieldErrors(err)))
+				return
+			}
+
+			if !opts.Responses {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &recorder{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if err := validateResponse(r, input, rec); err != nil {
+				log.Error("response does not match the API specification",
+					sl.Err(err),
+					slog.String("route", route.Method+" "+route.Path),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					sl.TraceID(r.Context()),
+				)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("response does not match the API specification: "+err.Error()))
+				return
+			}
+
+			rec.writeTo(w)
+		}
+
+		return http.HandlerFunc(fn)
+	}, nil
+}
+
+func isStreamed(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	for _, t := range streamed {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// validateResponse checks the recorded response. Redirects are not described
+// and only JSON bodies are checked.
+func validateResponse(r *http.Request, input *openapi3filter.RequestValidationInput, rec *recorder) error {
+	if rec.status >= 300 && rec.status < 400 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(rec.header.Get("Content-Type"))
+
+	return openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.status,
+		Header:                 rec.header,
+		Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+		Options: &openapi3filter.Options{
+			MultiError:          true,
+			ExcludeResponseBody: mediaType != content.JSON,
+		},
+	})
+}
+
+// fieldErrors flattens validation errors into the parameters and body fields
+// they are about.
+func fieldErrors(err error) []resp.FieldError {
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		var errs []resp.FieldError
+		for _, e := range err {
+			errs = append(errs, fieldErrors(e)...)
+		}
+		return errs
+	case *openapi3filter.RequestError:
+		return requestErrors(err)
+	}
+	return []resp.FieldError{{In: "request", Reason: err.Error()}}
+}
+
+func requestErrors(reqErr *openapi3filter.RequestError) []resp.FieldError {
+	in, name := "body", ""
+	if reqErr.Parameter != nil {
+		in, name = reqErr.Parameter.In, reqErr.Parameter.Name
+	}
+
+	causes := []error{reqErr.Err}
+	if multi, ok := reqErr.Err.(openapi3.MultiError); ok {
+		causes = multi
+	}
+
+	var errs []resp.FieldError
+	for _, cause := range causes {
+		fieldErr := resp.FieldError{In: in, Name: name, Reason: reqErr.Reason}
+
+		var schemaErr *openapi3.SchemaError
+		switch {
+		case errors.As(cause, &schemaErr):
+			fieldErr.Reason = schemaErr.Reason
+			if reqErr.Parameter == nil {
+				fieldErr.Name = strings.Join(schemaErr.JSONPointer(), ".")
+			}
+		case cause != nil:
+			fieldErr.Reason = cause.Error()
+		}
+
+		errs = append(errs, fieldErr)
+	}
+
+	return errs
+}
+
+// recorder buffers a response until it is validated.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	return rec.body.Write(p)
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	rec.status = status
+}
+
+func (rec *recorder) writeTo(w http.ResponseWriter) {
+	for k, v := range rec.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.status)
+	w.Write(rec.body.Bytes())
+}
@@ -0,0 +1,153 @@
+package validate
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"people-service/internal/http-server/openapi"
+	resp "people-service/internal/lib/api/response"
+)
+
+func newMiddleware(t *testing.T, opts Options) func(http.Handler) http.Handler {
+	t.Helper()
+
+	mw, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), openapi.New(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mw
+}
+
+// respond answers with the status and JSON body.
+func respond(status int, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	})
+}
+
+func TestResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   int
+	}{
+		{"matching", http.StatusBadRequest, `{"status":"Error","error":"invalid bucket"}`, http.StatusBadRequest},
+		{"wrong type", http.StatusOK, `{"status":"OK","total":"many"}`, http.StatusInternalServerError},
+		{"wrong error type", http.StatusBadRequest, `{"status":"Error","error":42}`, http.StatusInternalServerError},
+	}
+
+	mw := newMiddleware(t, Options{Responses: true})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mw(respond(tt.status, tt.body)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/person/stats", nil))
+
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == tt.status && rec.Body.String() != tt.body {
+				t.Errorf("got body %s, want it passed through", rec.Body)
+			}
+		})
+	}
+}
+
+func TestResponsesNotChecked(t *testing.T) {
+	mw := newMiddleware(t, Options{})
+
+	rec := httptest.NewRecorder()
+	mw(respond(http.StatusOK, `{"status":"OK","total":"many"}`)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/person/stats", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want responses passed through unchecked", rec.Code)
+	}
+}
+
+func TestRequestBodies(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		want   int
+		field  string
+	}{
+		{"valid", "/person", `{"name":"Ivan","surname":"Ivanov"}`, http.StatusOK, ""},
+		{"unknown field", "/person", `{"name":"Ivan","surname":"Ivanov","extra":1}`, http.StatusBadRequest, "extra"},
+		{"unknown field in batch item", "/person/batch", `[{"name":"Ivan","surname":"Ivanov","extra":1}]`, http.StatusBadRequest, "extra"},
+		{"too long", "/person", `{"name":"` + strings.Repeat("a", 256) + `","surname":"Ivanov"}`, http.StatusBadRequest, "name"},
+	}
+
+	mw := newMiddleware(t, Options{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			mw(respond(http.StatusOK, `{"status":"OK"}`)).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.field == "" {
+				return
+			}
+
+			var body resp.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range body.Errors {
+				if e.Name == tt.field || strings.Contains(e.Reason, `"`+tt.field+`"`) {
+					return
+				}
+			}
+			t.Errorf("errors %+v do not name %s", body.Errors, tt.field)
+		})
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	mw := newMiddleware(t, Options{MaxBodyBytes: 16})
+
+	req := httptest.NewRequest(http.MethodPost, "/person", strings.NewReader(`{"name":"Ivan","surname":"Ivanov"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	mw(respond(http.StatusOK, `{"status":"OK"}`)).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestStreamedBodiesSkipped(t *testing.T) {
+	mw := newMiddleware(t, Options{MaxBodyBytes: 16})
+
+	body := `{"name":"Ivan","surname":"Ivanov"}` + "\n" + `{"name":"Anna","surname":"Ivanova"}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/person/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	var read string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		read = string(b)
+	})
+
+	rec := httptest.NewRecorder()
+	mw(handler).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || read != body {
+		t.Fatalf("got status %d and body %q, want the stream passed to the handler uncapped", rec.Code, read)
+	}
+}
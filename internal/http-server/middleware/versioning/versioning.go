@@ -0,0 +1,178 @@
+package versioning
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/api/version"
+	"people-service/internal/lib/logger/sl"
+)
+
+type Options struct {
+	// Sunset is when version 1 stops being served; zero if not decided.
+	Sunset time.Time
+}
+
+// New picks the API version of a request from its path prefix (/v1, /v2) or,
+// for unversioned paths, from the Accept header, and keeps it in the request
+// context. Version 1 responses are marked deprecated; JSON responses of later
+// versions are wrapped in a resp.Envelope.
+func New(log *slog.Logger, opts Options) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/versioning"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			v := version.FromPath(r.URL.Path)
+			if v == 0 {
+				w.Header().Add("Vary", "Accept")
+
+				var err error
+				v, err = version.FromAccept(r.Header.Get("Accept"))
+				if err != nil {
+					render.Status(r, http.StatusNotAcceptable)
+					render.JSON(w, r, resp.Error(err.Error()+"; supported versions are "+supported()))
+					return
+				}
+				if v == 0 {
+					v = version.Default
+				}
+			}
+
+			w.Header().Set("API-Version", strconv.Itoa(v))
+			if v == version.V1 {
+				deprecate(w, r, opts.Sunset)
+			}
+
+			r = r.WithContext(version.WithVersion(r.Context(), v))
+
+			if v == version.V1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ew := &envelopeWriter{ResponseWriter: w}
+			next.ServeHTTP(ew, r)
+			if err := ew.finish(); err != nil {
+				log.Error("failed to wrap response",
+					sl.Err(err),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					sl.TraceID(r.Context()),
+				)
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// deprecate sets the Deprecation and Sunset headers (RFC 9745, RFC 8594) and
+// links the same resource in the latest version. Deprecation is a structured
+// field date, "@" followed by Unix seconds.
+func deprecate(w http.ResponseWriter, r *http.Request, sunset time.Time) {
+	w.Header().Set("Deprecation", "@"+strconv.FormatInt(version.V1Deprecated.Unix(), 10))
+	if !sunset.IsZero() {
+		w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+
+	path := r.URL.Path
+	if version.FromPath(path) != 0 {
+		_, path, _ = strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		path = "/" + path
+	}
+	w.Header().Add("Link", "<"+version.Prefix(version.Latest)+path+`>; rel="successor-version"`)
+}
+
+func supported() string {
+	var versions []string
+	for _, v := range version.Supported {
+		versions = append(versions, strconv.Itoa(v))
+	}
+	return strings.Join(versions, ", ")
+}
+
+// envelopeWriter holds back JSON responses until the handler is done, so that
+// finish can wrap them; other responses pass through as they are written.
+type envelopeWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	passthrough bool
+	body        bytes.Buffer
+}
+
+func (w *envelopeWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if mediaType != "application/json" || status < http.StatusOK || status == http.StatusNoContent {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *envelopeWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *envelopeWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && w.passthrough {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (w *envelopeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *envelopeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("versioning: hijacking is not supported")
+}
+
+// finish writes the held back JSON response wrapped in an envelope. A body
+// that cannot be wrapped is written unchanged.
+func (w *envelopeWriter) finish() error {
+	if !w.wroteHeader || w.passthrough {
+		return nil
+	}
+
+	body, err := resp.Envelop(w.body.Bytes())
+	if err != nil {
+		body = w.body.Bytes()
+	}
+
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	if _, werr := w.ResponseWriter.Write(body); werr != nil && err == nil {
+		err = werr
+	}
+
+	return err
+}
@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/validation"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas derives schemas from Go types the way encoding/json sees them.
+// Named structs become components named after their package and type, e.g.
+// "models.Person", so the document follows the structs it describes.
+type schemas map[string]*Schema
+
+// ref returns a schema of the type of v, registering components as needed.
+func (s schemas) ref(v interface{}) *Schema {
+	return s.of(reflect.TypeOf(v))
+}
+
+// closed is ref for request bodies: an inline copy of the schema of v, and of
+// the schemas it refers to, that allows no properties besides the listed ones.
+// The components stay open, as responses share them.
+func (s schemas) closed(v interface{}) *Schema {
+	return s.closedCopy(s.ref(v))
+}
+
+func (s schemas) closedCopy(schema *Schema) *Schema {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		schema = s[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+
+	closed := *schema
+	closed.Items = s.closedCopy(schema.Items)
+	if schema.Properties != nil {
+		closed.Properties = make(map[string]*Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			closed.Properties[name] = s.closedCopy(property)
+		}
+	}
+	if closed.Type == "object" && closed.AdditionalProperties == nil {
+		closed.AdditionalProperties = false
+	}
+	return &closed
+}
+
+func (s schemas) of(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		schema := s.of(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := s[name]; !ok {
+			// Reserve the name first so that recursive types terminate.
+			s[name] = nil
+			s[name] = s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	// Interfaces can hold anything.
+	return &Schema{}
+}
+
+// object lists the JSON properties of a struct, inlining embedded structs.
+// Fields validated as required are marked required.
+func (s schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := s.object(f.Type)
+			for k, v := range embedded.Properties {
+				schema.Properties[k] = v
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		property := s.of(f.Type)
+		schema.Properties[name] = property
+
+		rules := strings.Split(f.Tag.Get("validate"), ",")
+		for _, rule := range rules {
+			if rule == "required" {
+				schema.Required = append(schema.Required, name)
+			}
+			for _, r := range validation.Rules(rule) {
+				constrain(property, r)
+			}
+		}
+		// Empty values skip the rules of omitempty fields.
+		if rules[0] == "omitempty" && property.Enum != nil {
+			property.Enum = append(property.Enum, "")
+		}
+	}
+
+	return schema
+}
+
+// constrain adds the schema equivalent of a validator rule. Rules without
+// one, such as personname, are left to the handlers.
+func constrain(schema *Schema, rule string) {
+	tag, param, _ := strings.Cut(rule, "=")
+	n, err := strconv.Atoi(param)
+
+	switch {
+	case tag == "max" && err == nil && schema.Type == "string":
+		schema.MaxLength = &n
+	case tag == "max" && err == nil:
+		schema.Maximum = float(float64(n))
+	case tag == "min" && err == nil && schema.Type != "string":
+		schema.Minimum = float(float64(n))
+	case tag == "oneof":
+		for _, v := range strings.Fields(param) {
+			schema.Enum = append(schema.Enum, v)
+		}
+	case tag == validation.TagGender:
+		for _, g := range models.Genders {
+			schema.Enum = append(schema.Enum, g)
+		}
+	case tag == "iso3166_1_alpha2":
+		schema.Pattern = "^([A-Z]{2})?$"
+	}
+}
+
+func float(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,24 @@
+package openapi
+
+import (
+	"testing"
+
+	"people-service/internal/http-server/handlers/person/merge"
+)
+
+func TestClosedLeavesComponentsOpen(t *testing.T) {
+	s := make(schemas)
+	body := s.closed(merge.Request{})
+	if body.AdditionalProperties != false {
+		t.Errorf("request body allows additional properties")
+	}
+	if resolve := body.Properties["resolve"]; resolve == nil || resolve.AdditionalProperties != false {
+		t.Errorf("nested schema of the request body allows additional properties")
+	}
+
+	for name, component := range s {
+		if component.AdditionalProperties != nil {
+			t.Errorf("component %s was closed", name)
+		}
+	}
+}
@@ -0,0 +1,500 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/http-server/handlers/person/batch"
+	"people-service/internal/http-server/handlers/person/bulkdelete"
+	"people-service/internal/http-server/handlers/person/bulkupdate"
+	"people-service/internal/http-server/handlers/person/delete"
+	"people-service/internal/http-server/handlers/person/duplicates"
+	"people-service/internal/http-server/handlers/person/get"
+	"people-service/internal/http-server/handlers/person/importcsv"
+	"people-service/internal/http-server/handlers/person/merge"
+	"people-service/internal/http-server/handlers/person/save"
+	"people-service/internal/http-server/handlers/person/search"
+	"people-service/internal/http-server/handlers/person/stats"
+	"people-service/internal/http-server/handlers/person/update"
+	"people-service/internal/http-server/middleware/authn"
+	"people-service/internal/http-server/middleware/idempotency"
+	"people-service/internal/lib/api/content"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/api/version"
+	"people-service/internal/lib/export"
+	"people-service/internal/lib/routing"
+)
+
+const (
+	personPath = "/person/{" + routing.PersonIdParam + "}"
+	reportPath = "/person/import/reports/{" + routing.ReportIdParam + "}"
+)
+
+// New describes the /person routes.
+func New() *Document {
+	s := make(schemas)
+	errorResponse := s.ref(resp.Response{})
+	problem := s.ref(resp.Problem{})
+
+	// Items of get.Response are people, or their projections if fields
+	// are requested.
+	people := &Schema{
+		Type:        "array",
+		Items:       s.ref(models.Person{}),
+		Description: "People, holding only the requested columns when " + routing.FieldsParam + " is set.",
+	}
+	page := s.ref(get.Response{})
+	s["get.Response"].Properties["items"] = people
+
+	personId := pathParam(routing.PersonIdParam, "Person id. Reads of ids of people merged into another one redirect to it with 308; other methods get 410.", &Schema{Type: "integer"})
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title: "People service",
+			Description: "Stores people and enriches them with age, gender and nationality.\n\n" +
+				"Routes are served under /v1 and /v2. Without a prefix the version is taken from the Accept header, " +
+				"e.g. " + version.MediaType(version.V2) + " or application/json; version=2, and is 1 by default. " +
+				"Schemas below describe version 1 bodies; version 2 wraps JSON bodies in {\"data\": ..., \"error\": {\"message\", \"details\"}} " +
+				"and answers the errors version 1 reports with 200 with a 4xx or 5xx status.",
+			Version: "2.0.0",
+		},
+		Servers: servers(),
+		Paths: map[string]PathItem{
+			"/person": {
+				"get": {
+					Summary:    "List people",
+					Parameters: concat(filterParams(), pageParams(), []Parameter{fieldsParam()}),
+					Responses: map[string]Response{
+						"200": pageResponse(people, page),
+						"400": jsonResponse("Invalid query parameters", errorResponse),
+						"406": jsonResponse("None of the accepted media types is supported", errorResponse),
+						"500": jsonResponse("Failed to get people (version 2)", errorResponse),
+					},
+				},
+				"post": {
+					Summary:     "Create a person, enriching missing attributes",
+					RequestBody: jsonBody(s.closed(save.Request{})),
+					Responses: map[string]Response{
+						"200": jsonResponse("Id of the created person, or an error (version 1)", s.ref(save.Response{})),
+						"201": jsonResponse("Id of the created person; Location points to it (version 2)", s.ref(save.Response{})),
+						"400": jsonResponse("Invalid request", errorResponse),
+						"409": jsonResponse("The person already exists; id is the existing person", s.ref(save.Response{})),
+						"500": jsonResponse("Failed to add the person (version 2)", errorResponse),
+					},
+				},
+				"patch": {
+					Summary:     "Update every person matching the filters",
+					Parameters:  concat(filterParams(), bulkParams()),
+					RequestBody: jsonBody(s.closed(bulkupdate.Request{})),
+					Responses: map[string]Response{
+						"200": jsonResponse("Affected count, with a preview and confirm token on a dry run", s.ref(bulkupdate.Response{})),
+						"400": jsonResponse("Invalid filters or changes, no filters, or paging or sort parameters", errorResponse),
+						"409": jsonResponse("The confirm token does not match the request or has expired", errorResponse),
+					},
+				},
+				"delete": {
+					Summary:    "Delete every person matching the filters",
+					Parameters: concat(filterParams(), bulkParams()),
+					Responses: map[string]Response{
+						"200": jsonResponse("Affected count, with a preview and confirm token on a dry run", s.ref(bulkdelete.Response{})),
+						"400": jsonResponse("Invalid filters, no filters, or paging or sort parameters", errorResponse),
+						"409": jsonResponse("The confirm token does not match the request or has expired", errorResponse),
+					},
+				},
+			},
+			"/person/batch": {
+				"post": {
+					Summary: "Create many people",
+					Parameters: []Parameter{
+						queryParam(routing.ModeParam, "Stop at the first failure or save what can be saved.", enum(batch.ModeAtomic, batch.ModeBestEffort)),
+					},
+					RequestBody: &RequestBody{
+						Required: true,
+						Content: map[string]MediaType{
+							content.JSON: {Schema: &Schema{Type: "array", Items: s.closed(save.Request{})}},
+							// One object per line, checked by the handler as it reads them.
+							content.NDJSON: {Schema: &Schema{Type: "array", Items: s.closed(save.Request{})}},
+						},
+					},
+					Responses: map[string]Response{
+						"200": jsonResponse("Every person is saved", s.ref(batch.Response{})),
+						"207": jsonResponse("Some people are not saved", s.ref(batch.Response{})),
+						"400": jsonResponse("Invalid request", errorResponse),
+						"413": jsonResponse("The body is larger than 4 KiB per item allowed", errorResponse),
+					},
+				},
+			},
+			"/person/import": {
+				"post": {
+					Summary: "Import people from a CSV file",
+					RequestBody: &RequestBody{
+						Required: true,
+						Content: map[string]MediaType{
+							"multipart/form-data": {Schema: &Schema{
+								Type: "object",
+								Properties: map[string]*Schema{
+									"file":    {Type: "string", Format: "binary"},
+									"mapping": {Type: "string", Description: `CSV columns to person fields, e.g. "First Name:name,Last Name:surname".`},
+									"enrich":  {Type: "string", Description: "true to look missing attributes up upstream."},
+								},
+								Required: []string{"file"},
+							}},
+						},
+					},
+					Responses: map[string]Response{
+						"200": jsonResponse("Import summary", s.ref(importcsv.Response{})),
+						"400": jsonResponse("Invalid upload or mapping", errorResponse),
+					},
+				},
+			},
+			reportPath: {
+				"get": {
+					Summary:    "Download the rejected rows of an import",
+					Parameters: []Parameter{pathParam(routing.ReportIdParam, "Report id from the import response.", &Schema{Type: "string"})},
+					Responses: map[string]Response{
+						"200": {Description: "Rejected rows with the reason", Content: map[string]MediaType{content.CSV: {Schema: &Schema{Type: "string"}}}},
+						"404": jsonResponse("Unknown or expired report", errorResponse),
+					},
+				},
+			},
+			"/person/export": {
+				"get": {
+					Summary: "Export people matching the filters",
+					Parameters: concat(filterParams(), []Parameter{
+						sortParam(),
+						queryParam(routing.FormatParam, "File format.", enum(export.FormatCSV, export.FormatNDJSON, export.FormatXLSX)),
+						columnsParam(routing.ColumnsParam, "Columns to export, all by default."),
+					}),
+					Responses: map[string]Response{
+						"200": {Description: "The people as a file attachment", Content: map[string]MediaType{
+							export.ContentType(export.FormatCSV):    {Schema: &Schema{Type: "string"}},
+							export.ContentType(export.FormatNDJSON): {Schema: &Schema{Type: "string"}},
+							export.ContentType(export.FormatXLSX):   {Schema: &Schema{Type: "string", Format: "binary"}},
+						}},
+						"400": jsonResponse("Invalid format, columns or filters", errorResponse),
+					},
+				},
+			},
+			"/person/search": {
+				"get": {
+					Summary: "Search people by name",
+					Parameters: []Parameter{
+						required(queryParam(routing.QueryParam, "Words of the name, surname or patronymic; spelling and transliteration may differ.", &Schema{Type: "string"})),
+						queryParam(routing.LimitParam, "Maximum number of results.", intRange(1, 100)),
+					},
+					Responses: map[string]Response{
+						"200": negotiated("Best matches first", s.ref(search.Response{})),
+						"400": jsonResponse("Invalid query", errorResponse),
+						"406": jsonResponse("None of the accepted media types is supported", errorResponse),
+					},
+				},
+			},
+			"/person/stats": {
+				"get": {
+					Summary: "Aggregate statistics of people matching the filters",
+					Parameters: concat(filterParams(), []Parameter{
+						queryParam(routing.BucketParam, "Width of age buckets.", intRange(1, 150)),
+					}),
+					Responses: map[string]Response{
+						"200": jsonResponse("Statistics", s.ref(stats.Response{})),
+						"400": jsonResponse("Invalid parameters", errorResponse),
+					},
+				},
+			},
+			"/person/merge": {
+				"post": {
+					Summary:     "Merge one person into another",
+					RequestBody: jsonBody(s.closed(merge.Request{})),
+					Responses: map[string]Response{
+						"200": jsonResponse("The merged person", s.ref(merge.Response{})),
+						"400": jsonResponse("Invalid request", errorResponse),
+						"404": jsonResponse("A person is not found", errorResponse),
+						"409": jsonResponse("The merged person conflicts with another one; id is the existing person", s.ref(merge.Response{})),
+					},
+				},
+			},
+			personPath: {
+				"get": {
+					Summary:    "Get a person",
+					Parameters: []Parameter{personId, fieldsParam()},
+					Responses: map[string]Response{
+						"200": negotiated("The person", s.ref(models.Person{})),
+						"400": jsonResponse("Invalid id or fields", errorResponse),
+						"404": jsonResponse("Person not found", errorResponse),
+						"406": jsonResponse("None of the accepted media types is supported", errorResponse),
+					},
+				},
+				"put": {
+					Summary:     "Update a person",
+					Parameters:  []Parameter{personId},
+					RequestBody: jsonBody(s.closed(update.Request{})),
+					Responses: map[string]Response{
+						"200": jsonResponse("Id of the updated person, or an error (version 1)", s.ref(update.Response{})),
+						"400": jsonResponse("Invalid id or request", errorResponse),
+						"409": jsonResponse("The update makes the person a duplicate; id is the existing person", s.ref(update.Response{})),
+						"410": jsonResponse("The person was merged into another one (version 2)", errorResponse),
+						"500": jsonResponse("Failed to update the person (version 2)", errorResponse),
+					},
+				},
+				"delete": {
+					Summary:    "Delete a person",
+					Parameters: []Parameter{personId},
+					Responses: map[string]Response{
+						"200": jsonResponse("Status of the deletion, or an error (version 1)", s.ref(delete.Response{})),
+						"400": jsonResponse("Invalid id (version 2)", errorResponse),
+						"410": jsonResponse("The person was merged into another one (version 2)", errorResponse),
+						"500": jsonResponse("Failed to delete the person (version 2)", errorResponse),
+					},
+				},
+			},
+			personPath + "/duplicates": {
+				"get": {
+					Summary: "Find likely duplicates of a person",
+					Parameters: []Parameter{
+						personId,
+						queryParam(routing.ThresholdParam, "Minimum similarity of names.", numberRange(0, 1)),
+						queryParam(routing.LimitParam, "Maximum number of duplicates.", intRange(1, 100)),
+					},
+					Responses: map[string]Response{
+						"200": negotiated("Most similar first", s.ref(duplicates.Response{})),
+						"400": jsonResponse("Invalid parameters", errorResponse),
+						"404": jsonResponse("Person not found", errorResponse),
+						"406": jsonResponse("None of the accepted media types is supported", errorResponse),
+					},
+				},
+			},
+		},
+		Components: Components{
+			Schemas: s,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"apiKey": {
+					Type:        "apiKey",
+					Description: "API key issued with people-cli apikey issue. It can also be sent as a bearer token.",
+					Name:        authn.APIKeyHeader,
+					In:          "header",
+				},
+				"bearer": {
+					Type:         "http",
+					Description:  "JWT signed with HS256 or RS256; sub names the caller.",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+				},
+			},
+		},
+		Security: []SecurityRequirement{{"apiKey": {}}, {"bearer": {}}},
+	}
+
+	for _, op := range []*Operation{
+		doc.Paths["/person"]["post"],
+		doc.Paths["/person/batch"]["post"],
+		doc.Paths["/person/merge"]["post"],
+	} {
+		idempotent(op, errorResponse)
+	}
+
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			op.Responses["401"] = jsonResponse("Missing or invalid credentials", errorResponse)
+			op.Responses["403"] = problemResponse("The role of the caller does not allow the operation", problem)
+			op.Responses["429"] = jsonResponse("Rate limit exceeded; retry after Retry-After seconds", errorResponse)
+			if _, ok := op.Responses["413"]; !ok && op.RequestBody != nil {
+				op.Responses["413"] = jsonResponse("The request body is too large", errorResponse)
+			}
+			op.Tags = []string{"person"}
+			op.OperationId = fmt.Sprintf("%s %s", method, path)
+		}
+	}
+
+	// Only admins may change enrichment-derived attributes.
+	doc.Paths[personPath]["put"].Responses["403"] = problemResponse(
+		"The role of the caller does not allow the operation, or the update changes the enrichment-derived attributes listed in fields",
+		problem,
+	)
+	// Only editors may query enrichment-derived attributes.
+	doc.Paths["/person"]["get"].Responses["403"] = problemResponse(
+		"The role of the caller does not allow the operation, or the query filters or sorts by the enrichment-derived attributes listed in fields",
+		problem,
+	)
+
+	return doc
+}
+
+// servers lists a server for every supported version, newest first, and the
+// unprefixed one, so that requests naming another version match no route.
+func servers() []Server {
+	var list []Server
+	for i := len(version.Supported) - 1; i >= 0; i-- {
+		v := version.Supported[i]
+		description := fmt.Sprintf("Version %d", v)
+		if v < version.Latest {
+			description += ", deprecated"
+		}
+		list = append(list, Server{URL: version.Prefix(v), Description: description})
+	}
+	return append(list, Server{URL: "/", Description: "Version from the Accept header"})
+}
+
+// filterParams are the filters of GET /person, shared by the routes that
+// select people the same way.
+func filterParams() []Parameter {
+	stringFilter := "Exact match, or [not:][op:]operand with op one of eq, in, prefix, contains, like."
+	setFilter := "Exact match, or [not:]in:a,b,c."
+	dateFilter := "RFC 3339 time or YYYY-MM-DD date."
+
+	return []Parameter{
+		queryParam(routing.IdParam, "Id, or in:1,2,3.", &Schema{Type: "string"}),
+		queryParam(routing.NameParam, stringFilter, &Schema{Type: "string"}),
+		queryParam(routing.SurnameParam, stringFilter, &Schema{Type: "string"}),
+		queryParam(routing.PatronymicParam, stringFilter, &Schema{Type: "string"}),
+		queryParam(routing.AgeParam, "Exact age.", intRange(0, -1)),
+		queryParam(routing.AgeMinParam, "Minimum age.", intRange(0, -1)),
+		queryParam(routing.AgeMaxParam, "Maximum age.", intRange(0, -1)),
+		queryParam(routing.GenderParam, setFilter, &Schema{Type: "string"}),
+		queryParam(routing.NationalityParam, setFilter, &Schema{Type: "string"}),
+		queryParam(routing.CreatedAfterParam, dateFilter, &Schema{Type: "string"}),
+		queryParam(routing.CreatedBeforeParam, dateFilter, &Schema{Type: "string"}),
+		queryParam(routing.UpdatedAfterParam, dateFilter, &Schema{Type: "string"}),
+		queryParam(routing.UpdatedBeforeParam, dateFilter, &Schema{Type: "string"}),
+	}
+}
+
+// idempotent documents an operation served through the idempotency
+// middleware.
+func idempotent(op *Operation, errorResponse *Schema) {
+	maxLength := idempotency.MaxKeyLength
+	op.Parameters = append(op.Parameters, Parameter{
+		Name:        idempotency.Header,
+		In:          "header",
+		Description: "Key of the request, repeated on retries. The first response is stored and replayed with " + idempotency.ReplayedHeader + ": true.",
+		Schema:      &Schema{Type: "string", MaxLength: &maxLength},
+	})
+
+	inProgress := "A request with the same " + idempotency.Header + " is in progress"
+	if conflict, ok := op.Responses["409"]; ok {
+		conflict.Description += ", or " + strings.ToLower(inProgress[:1]) + inProgress[1:]
+		op.Responses["409"] = conflict
+	} else {
+		op.Responses["409"] = jsonResponse(inProgress, errorResponse)
+	}
+	op.Responses["422"] = jsonResponse(idempotency.Header+" was used for a different request", errorResponse)
+}
+
+func sortParam() Parameter {
+	return queryParam(routing.SortParam, fmt.Sprintf(
+		"Comma separated columns, prefixed with - for descending order, e.g. -%s,%s. Ties are broken by %s.",
+		routing.AgeParam, routing.SurnameParam, routing.IdParam,
+	), &Schema{Type: "string"})
+}
+
+func pageParams() []Parameter {
+	return []Parameter{
+		sortParam(),
+		queryParam(routing.AfterParam, "Cursor of the next page.", &Schema{Type: "string"}),
+		queryParam(routing.BeforeParam, "Cursor of the previous page.", &Schema{Type: "string"}),
+		queryParam(routing.OffsetParam, "Rows to skip; cannot be combined with a cursor.", intRange(0, -1)),
+		queryParam(routing.SizeParam, "Page size.", intRange(0, -1)),
+		queryParam(routing.LimitParam, "Older alias of "+routing.SizeParam+".", intRange(0, -1)),
+	}
+}
+
+func bulkParams() []Parameter {
+	return []Parameter{
+		queryParam(routing.DryRunParam, "Report what would change and return a confirm token.", &Schema{Type: "boolean"}),
+		queryParam(routing.ConfirmParam, "Token from the dry run; required to apply the change.", &Schema{Type: "string"}),
+	}
+}
+
+func fieldsParam() Parameter {
+	return columnsParam(routing.FieldsParam, "Columns to return, all by default.")
+}
+
+// columnsParam is a comma separated list of export columns.
+func columnsParam(name, description string) Parameter {
+	var names []interface{}
+	for _, c := range export.AllColumns() {
+		names = append(names, c.Name)
+	}
+
+	explode := false
+	p := queryParam(name, description, &Schema{Type: "array", Items: &Schema{Type: "string", Enum: names}})
+	p.Explode = &explode
+	return p
+}
+
+func queryParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func pathParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: schema}
+}
+
+func required(p Parameter) Parameter {
+	p.Required = true
+	return p
+}
+
+func enum(values ...string) *Schema {
+	schema := &Schema{Type: "string"}
+	for _, v := range values {
+		schema.Enum = append(schema.Enum, v)
+	}
+	return schema
+}
+
+// intRange returns an integer schema; a negative max means unbounded.
+func intRange(min, max int) *Schema {
+	schema := &Schema{Type: "integer", Minimum: float(float64(min))}
+	if max >= 0 {
+		schema.Maximum = float(float64(max))
+	}
+	return schema
+}
+
+func numberRange(min, max float64) *Schema {
+	return &Schema{Type: "number", Minimum: float(min), Maximum: float(max)}
+}
+
+func concat(lists ...[]Parameter) []Parameter {
+	var params []Parameter
+	for _, l := range lists {
+		params = append(params, l...)
+	}
+	return params
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]MediaType{content.JSON: {Schema: schema}}}
+}
+
+func jsonResponse(description string, schema *Schema) Response {
+	return Response{Description: description, Content: map[string]MediaType{content.JSON: {Schema: schema}}}
+}
+
+func problemResponse(description string, schema *Schema) Response {
+	return Response{Description: description, Content: map[string]MediaType{resp.ProblemContentType: {Schema: schema}}}
+}
+
+// pageResponse describes GET /person. Version 1 JSON is the bare array of
+// people it has always been; XML and version 2 JSON carry the whole page.
+func pageResponse(people, page *Schema) Response {
+	response := negotiated("A page of people. Link headers point to the next and previous pages. "+
+		"Version 1 JSON is the array of people, with their total in the "+get.TotalCountHeader+" header; "+
+		"version 2 JSON has the page, as in XML, in data.", page)
+	response.Content[content.JSON] = MediaType{Schema: people}
+	return response
+}
+
+// negotiated describes a response rendered by content.Render. CSV and NDJSON
+// carry only the people of the body, one per row.
+func negotiated(description string, schema *Schema) Response {
+	row := &Schema{Type: "string"}
+	return Response{Description: description, Content: map[string]MediaType{
+		content.JSON:   {Schema: schema},
+		content.XML:    {Schema: schema},
+		content.CSV:    {Schema: row},
+		content.NDJSON: {Schema: row},
+	}}
+}
@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// Verify checks that the document describes exactly the /person routes of
+// the router under each of its servers, so that the two cannot drift apart
+// unnoticed.
+func Verify(routes chi.Routes, doc *Document) error {
+	served := make(map[string]map[string]bool)
+	for _, server := range doc.Servers {
+		served[strings.TrimSuffix(server.URL, "/")] = make(map[string]bool)
+	}
+
+	err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		for base, routes := range served {
+			path, found := strings.CutPrefix(route, base)
+			if found && (path == "/person" || strings.HasPrefix(path, "/person/")) {
+				routes[method+" "+path] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("openapi: failed to walk routes: %w", err)
+	}
+
+	described := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item {
+			described[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var problems []string
+	for base, routes := range served {
+		for route := range routes {
+			if !described[route] {
+				problems = append(problems, "undocumented route "+route+" under "+base+"/")
+			}
+		}
+		for route := range described {
+			if !routes[route] {
+				problems = append(problems, "documented route "+route+" is not served under "+base+"/")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("openapi: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package content
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/render"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/export"
+)
+
+// Media types a person response can be rendered as, in order of preference.
+const (
+	JSON   = "application/json"
+	XML    = "application/xml"
+	CSV    = "text/csv"
+	NDJSON = "application/x-ndjson"
+)
+
+var supported = []string{JSON, XML, CSV, NDJSON}
+
+type ctxKey struct{}
+
+// Negotiate picks the response media type from the Accept header and keeps it
+// in the request context for Render. Requests accepting none of the supported
+// types are answered with 406.
+func Negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		mediaType := Select(r.Header.Get("Accept"))
+		if mediaType == "" {
+			render.Status(r, http.StatusNotAcceptable)
+			render.JSON(w, r, resp.Error("supported media types are "+strings.Join(supported, ", ")))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ctxKey{}, mediaType)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Select returns the supported media type the Accept header prefers, JSON
+// for an empty header, or "" if none is acceptable.
+func Select(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return JSON
+	}
+
+	type candidate struct {
+		mediaType string
+		q         float64
+	}
+	var candidates []candidate
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		mediaRange = strings.ToLower(strings.TrimSpace(mediaRange))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(name) == "q" {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		for _, mediaType := range supported {
+			if matches(mediaRange, mediaType) {
+				candidates = append(candidates, candidate{mediaType, q})
+				break
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	return candidates[0].mediaType
+}
+
+// matches reports whether the media range, e.g. "text/*", covers the type.
+// JSON based types such as application/vnd.people.v2+json are JSON.
+func matches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	if mediaType == JSON && strings.HasSuffix(mediaRange, "+json") {
+		return true
+	}
+	prefix, found := strings.CutSuffix(mediaRange, "/*")
+	return found && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// FromContext returns the media type chosen by Negotiate, JSON by default.
+func FromContext(ctx context.Context) string {
+	if mediaType, ok := ctx.Value(ctxKey{}).(string); ok {
+		return mediaType
+	}
+	return JSON
+}
+
+// Body is a response in every supported media type. Value is rendered as JSON
+// or as XML under the Root element. CSV and NDJSON have no room for an
+// envelope, so they carry only the People, projected onto Columns.
+type Body struct {
+	Value   interface{}
+	Root    string
+	People  []models.Person
+	Columns []export.Column
+}
+
+// Render writes the body in the media type chosen by Negotiate, with the
+// status set by render.Status.
+func Render(w http.ResponseWriter, r *http.Request, body Body) {
+	mediaType := FromContext(r.Context())
+
+	switch mediaType {
+	case XML:
+		w.Header().Set("Content-Type", XML+"; charset=utf-8")
+		writeStatus(w, r)
+		w.Write([]byte(xml.Header))
+		xml.NewEncoder(w).EncodeElement(body.Value, xml.StartElement{Name: xml.Name{Local: body.Root}})
+	case CSV, NDJSON:
+		format := export.FormatCSV
+		if mediaType == NDJSON {
+			format = export.FormatNDJSON
+		}
+		w.Header().Set("Content-Type", export.ContentType(format))
+		writeStatus(w, r)
+
+		writer, err := export.NewWriter(format, w, body.Columns)
+		if err != nil {
+			return
+		}
+		for _, p := range body.People {
+			if err := writer.Write(p); err != nil {
+				return
+			}
+		}
+		writer.Close()
+	default:
+		render.JSON(w, r, body.Value)
+	}
+}
+
+func writeStatus(w http.ResponseWriter, r *http.Request) {
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+}
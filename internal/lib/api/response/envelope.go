@@ -0,0 +1,106 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"people-service/internal/lib/api/version"
+)
+
+// Envelope is the shape of every JSON response from version 2 on. Data holds
+// the payload, Error is set when the request failed.
+type Envelope struct {
+	Data  json.RawMessage `json:"data"`
+	Error *EnvelopeError  `json:"error,omitempty"`
+}
+
+type EnvelopeError struct {
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// LegacyStatus sets the status of an error response that version 1 answers
+// with 200 OK. Version 1 keeps doing so; later versions get the status.
+func LegacyStatus(r *http.Request, status int) {
+	if version.FromContext(r.Context()) >= version.V2 {
+		render.Status(r, status)
+	}
+}
+
+// Envelop turns a version 1 body into an Envelope. Status, error and errors
+// of a Response move to Error; the remaining fields, in their order, are the
+// data. Bodies that are not a Response are the data as a whole.
+func Envelop(body []byte) ([]byte, error) {
+	const op = "response.Envelop"
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return json.Marshal(Envelope{Data: nullIfEmpty(trimmed)})
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var (
+		base Response
+		data bytes.Buffer
+	)
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		name, _ := key.(string)
+		switch name {
+		case "status":
+			err = json.Unmarshal(value, &base.Status)
+		case "error":
+			err = json.Unmarshal(value, &base.Error)
+		case "errors":
+			err = json.Unmarshal(value, &base.Errors)
+		default:
+			if data.Len() > 0 {
+				data.WriteByte(',')
+			}
+			k, _ := json.Marshal(name)
+			data.Write(k)
+			data.WriteByte(':')
+			data.Write(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if base.Status == "" {
+		return json.Marshal(Envelope{Data: trimmed})
+	}
+
+	var envelope Envelope
+	if data.Len() > 0 {
+		envelope.Data = json.RawMessage("{" + data.String() + "}")
+	}
+	if base.Status == StatusError {
+		envelope.Error = &EnvelopeError{Message: base.Error, Details: base.Errors}
+	}
+
+	return json.Marshal(envelope)
+}
+
+func nullIfEmpty(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	return body
+}
@@ -0,0 +1,43 @@
+package response
+
+import "testing"
+
+func TestEnvelop(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"ok with fields", `{"status":"OK","id":7,"items":[1,2]}`, `{"data":{"id":7,"items":[1,2]}}`},
+		{"ok without fields", `{"status":"OK"}`, `{"data":null}`},
+		{"error", `{"status":"Error","error":"person not found"}`, `{"data":null,"error":{"message":"person not found"}}`},
+		{"error with fields", `{"status":"Error","error":"person already exists","id":3}`, `{"data":{"id":3},"error":{"message":"person already exists"}}`},
+		{
+			"field errors",
+			`{"status":"Error","error":"invalid request","errors":[{"in":"body","name":"age","reason":"too big"}]}`,
+			`{"data":null,"error":{"message":"invalid request","details":[{"in":"body","name":"age","reason":"too big"}]}}`,
+		},
+		{"array", `[{"Id":1}]`, `{"data":[{"Id":1}]}`},
+		{"object without status", `{"total":3}`, `{"data":{"total":3}}`},
+		{"empty", ``, `{"data":null}`},
+		{"trailing newline", "{\"status\":\"OK\",\"id\":1}\n", `{"data":{"id":1}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Envelop([]byte(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvelopMalformed(t *testing.T) {
+	if _, err := Envelop([]byte(`{"status":`)); err == nil {
+		t.Error("expected an error for a truncated body")
+	}
+}
@@ -0,0 +1,39 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body. Fields lists the request or
+// response fields the problem is about.
+type Problem struct {
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	Status   int      `json:"status"`
+	Detail   string   `json:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty"`
+	Fields   []string `json:"fields,omitempty"`
+}
+
+// Forbidden returns the problem of a request the caller is not allowed to
+// make.
+func Forbidden(r *http.Request, detail string, fields ...string) Problem {
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(http.StatusForbidden),
+		Status:   http.StatusForbidden,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Fields:   fields,
+	}
+}
+
+// RenderProblem writes the problem with its status.
+func RenderProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -1,49 +1,85 @@
-package response
-
-import (
-	"fmt"
-	"strings"
-
-	"github.com/go-playground/validator/v10"
-)
-
-type Response struct {
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
-}
-
-const (
-	StatusOK    = "OK"
-	StatusError = "Error"
-)
-
-func OK() Response {
-	return Response{
-		Status: StatusOK,
-	}
-}
-
-func Error(msg string) Response {
-	return Response{
-		Status: StatusError,
-		Error:  msg,
-	}
-}
-
-func ValidationError(errs validator.ValidationErrors) Response {
-	var errMsgs []string
-
-	for _, err := range errs {
-		switch err.ActualTag() {
-		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
-		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not valid", err.Field()))
-		}
-	}
-
-	return Response{
-		Status: StatusError,
-		Error:  strings.Join(errMsgs, ", "),
-	}
-}
+package response
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/validation"
+)
+
+type Response struct {
+	Status string       `json:"status" xml:"status"`
+	Error  string       `json:"error,omitempty" xml:"error,omitempty"`
+	Errors []FieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+// FieldError is a part of a request that failed validation. In is path,
+// query, header or body; Name is the parameter name or the dotted path of
+// the body field.
+type FieldError struct {
+	In     string `json:"in" xml:"in"`
+	Name   string `json:"name,omitempty" xml:"name,omitempty"`
+	Reason string `json:"reason" xml:"reason"`
+}
+
+const (
+	StatusOK    = "OK"
+	StatusError = "Error"
+)
+
+func OK() Response {
+	return Response{
+		Status: StatusOK,
+	}
+}
+
+func Error(msg string) Response {
+	return Response{
+		Status: StatusError,
+		Error:  msg,
+	}
+}
+
+func InvalidRequest(errs []FieldError) Response {
+	return Response{
+		Status: StatusError,
+		Error:  "invalid request",
+		Errors: errs,
+	}
+}
+
+func ValidationError(errs validator.ValidationErrors) Response {
+	var errMsgs []string
+
+	for _, err := range errs {
+		switch err.ActualTag() {
+		case "required":
+			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
+		case "max":
+			if err.Kind() == reflect.String {
+				errMsgs = append(errMsgs, fmt.Sprintf("field %s must be at most %s characters long", err.Field(), err.Param()))
+			} else {
+				errMsgs = append(errMsgs, fmt.Sprintf("field %s must be at most %s", err.Field(), err.Param()))
+			}
+		case "min":
+			errMsgs = append(errMsgs, fmt.Sprintf("field %s must be at least %s", err.Field(), err.Param()))
+		case validation.TagPersonName:
+			errMsgs = append(errMsgs, fmt.Sprintf("field %s must consist of letters, hyphens, apostrophes and spaces", err.Field()))
+		case validation.TagGender:
+			errMsgs = append(errMsgs, fmt.Sprintf("field %s must be one of %s", err.Field(), strings.Join(models.Genders, ", ")))
+		case "iso3166_1_alpha2":
+			errMsgs = append(errMsgs, fmt.Sprintf("field %s must be an ISO 3166-1 alpha-2 country code, e.g. RU", err.Field()))
+		default:
+			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not valid", err.Field()))
+		}
+	}
+
+	return Response{
+		Status: StatusError,
+		Error:  strings.Join(errMsgs, ", "),
+	}
+}
@@ -0,0 +1,112 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API versions. V1 keeps the original response shapes and is deprecated; V2
+// wraps responses in an envelope and answers errors with matching statuses.
+const (
+	V1 = 1
+	V2 = 2
+
+	Default = V1
+	Latest  = V2
+)
+
+// Supported lists the versions the service answers, oldest first.
+var Supported = []int{V1, V2}
+
+// V1Deprecated is when version 2 was released and version 1 deprecated.
+var V1Deprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// mediaTypePrefix starts vendor media types naming a version, e.g.
+// application/vnd.people.v2+json.
+const mediaTypePrefix = "application/vnd.people.v"
+
+type ctxKey struct{}
+
+// MediaType returns the vendor media type asking for version v.
+func MediaType(v int) string {
+	return mediaTypePrefix + strconv.Itoa(v) + "+json"
+}
+
+// Prefix returns the path prefix routes of version v are mounted under.
+func Prefix(v int) string {
+	return "/v" + strconv.Itoa(v)
+}
+
+// IsSupported reports whether the service answers version v.
+func IsSupported(v int) bool {
+	for _, s := range Supported {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// FromPath returns the version named by the first path segment, e.g. 2 for
+// /v2/person, or 0 if the path is not versioned.
+func FromPath(path string) int {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	n, found := strings.CutPrefix(segment, "v")
+	if !found {
+		return 0
+	}
+	v, err := strconv.Atoi(n)
+	if err != nil || !IsSupported(v) {
+		return 0
+	}
+	return v
+}
+
+// FromAccept returns the version asked for by the Accept header, either with
+// a vendor media type (application/vnd.people.v2+json) or a version parameter
+// (application/json; version=2), or 0 if none is asked for.
+func FromAccept(accept string) (int, error) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		mediaRange = strings.ToLower(strings.TrimSpace(mediaRange))
+
+		value := ""
+		if rest, found := strings.CutPrefix(mediaRange, mediaTypePrefix); found {
+			value, _ = strings.CutSuffix(rest, "+json")
+		}
+		for _, param := range strings.Split(params, ";") {
+			name, v, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(name) == "version" {
+				value = strings.Trim(strings.TrimSpace(v), `"`)
+			}
+		}
+		if value == "" {
+			continue
+		}
+
+		v, err := strconv.Atoi(value)
+		if err != nil || !IsSupported(v) {
+			return 0, fmt.Errorf("unsupported API version %q", value)
+		}
+		return v, nil
+	}
+
+	return 0, nil
+}
+
+// WithVersion returns a copy of ctx carrying the version.
+func WithVersion(ctx context.Context, v int) context.Context {
+	return context.WithValue(ctx, ctxKey{}, v)
+}
+
+// FromContext returns the version the request is served with, Default if it
+// is not set.
+func FromContext(ctx context.Context) int {
+	if v, ok := ctx.Value(ctxKey{}).(int); ok {
+		return v
+	}
+	return Default
+}
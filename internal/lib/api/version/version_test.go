@@ -0,0 +1,56 @@
+package version
+
+import "testing"
+
+func TestFromAccept(t *testing.T) {
+	tests := []struct {
+		accept  string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"*/*", 0, false},
+		{"application/json", 0, false},
+		{"application/vnd.people.v1+json", V1, false},
+		{"application/vnd.people.v2+json", V2, false},
+		{"Application/Vnd.People.V2+JSON", V2, false},
+		{"application/json; version=2", V2, false},
+		{`application/json; charset=utf-8; version="1"`, V1, false},
+		{"text/csv, application/vnd.people.v2+json;q=0.9", V2, false},
+		{"application/vnd.people.v3+json", 0, true},
+		{"application/json; version=two", 0, true},
+		{"application/vnd.people.v+json", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := FromAccept(tt.accept)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("FromAccept(%q): got error %v, want error %v", tt.accept, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FromAccept(%q) = %d, want %d", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/v1/person", V1},
+		{"/v2/person/1", V2},
+		{"/v2", V2},
+		{"/person", 0},
+		{"/v3/person", 0},
+		{"/version/person", 0},
+		{"/", 0},
+	}
+
+	for _, tt := range tests {
+		if got := FromPath(tt.path); got != tt.want {
+			t.Errorf("FromPath(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Ways a principal authenticates.
+const (
+	MethodAPIKey    = "api_key"
+	MethodJWT       = "jwt"
+	MethodAnonymous = "anonymous"
+)
+
+// APIKeyPrefix starts every API key, telling them apart from JWTs.
+const APIKeyPrefix = "ps_"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Method  string
+	Role    Role
+}
+
+type ctxKey struct{}
+
+type observerKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal. Observers
+// registered with Observe on a parent context see it too.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	if observed, ok := ctx.Value(observerKey{}).(*Principal); ok {
+		*observed = p
+	}
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the principal of the request, if it is authenticated.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
+
+// ClientKey tells the callers of requests apart: by their principal or, when
+// they are anonymous, by their IP address.
+func ClientKey(r *http.Request) string {
+	if p, ok := FromContext(r.Context()); ok && p.Method != MethodAnonymous {
+		return p.Subject
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Observe lets middlewares running before authentication, such as the
+// request logger, learn the principal once the request is served: the
+// returned func reports the principal set on ctx or any context derived
+// from it.
+func Observe(ctx context.Context) (context.Context, func() (Principal, bool)) {
+	observed := &Principal{}
+	ctx = context.WithValue(ctx, observerKey{}, observed)
+	return ctx, func() (Principal, bool) {
+		return *observed, observed.Subject != ""
+	}
+}
+
+// GenerateAPIKey returns a new random API key. Only its hash is stored, so
+// the key is shown once.
+func GenerateAPIKey() (string, error) {
+	const op = "auth.GenerateAPIKey"
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the hash API keys are stored and looked up by.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether the credential looks like an API key rather than a
+// JWT.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Role is the set of privileges of a principal. Each role has the
+// privileges of the ones before it in Roles.
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+var Roles = []Role{RoleReader, RoleEditor, RoleAdmin}
+
+func ParseRole(value string) (Role, error) {
+	for _, r := range Roles {
+		if string(r) == value {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role %q", value)
+}
+
+// Allows reports whether the role has the privileges of the required one.
+func (r Role) Allows(required Role) bool {
+	return rank(r) >= rank(required)
+}
+
+func rank(r Role) int {
+	for i, role := range Roles {
+		if role == r {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// highest returns the most privileged of the known roles, "" if none is
+// known.
+func highest(values []string) Role {
+	var best Role
+	for _, v := range values {
+		if r, err := ParseRole(v); err == nil && rank(r) > rank(best) {
+			best = r
+		}
+	}
+	return best
+}
+
+// RoleOf returns the role of the principal of the request, "" if it is not
+// authenticated.
+func RoleOf(ctx context.Context) Role {
+	p, _ := FromContext(ctx)
+	return p.Role
+}
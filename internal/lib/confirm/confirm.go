@@ -0,0 +1,64 @@
+package confirm
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Confirmer issues tokens that bind a destructive request to the outcome its
+// dry run reported: the same method, filters and changes affecting the same
+// number of rows. A token stops matching once any of them differ, and expires
+// ttl after it is issued.
+type Confirmer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// New creates a confirmer signing with the secret. An empty secret is
+// replaced with a random one, so tokens do not survive a restart.
+func New(secret string, ttl time.Duration) (*Confirmer, error) {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("confirm.New: %w", err)
+		}
+	}
+	return &Confirmer{secret: key, ttl: ttl}, nil
+}
+
+// Token is the issue time in Unix seconds and the signature, joined by a dot.
+func (c *Confirmer) Token(method string, filters url.Values, changes []byte, affected int) string {
+	issued := time.Now().Unix()
+	return strconv.FormatInt(issued, 10) + "." + c.sign(issued, method, filters, changes, affected)
+}
+
+func (c *Confirmer) Valid(token, method string, filters url.Values, changes []byte, affected int) bool {
+	ts, signature, found := strings.Cut(token, ".")
+	if !found {
+		return false
+	}
+	issued, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(issued, 0)); age < 0 || age > c.ttl {
+		return false
+	}
+
+	expected := c.sign(issued, method, filters, changes, affected)
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+func (c *Confirmer) sign(issued int64, method string, filters url.Values, changes []byte, affected int) string {
+	mac := hmac.New(sha256.New, c.secret)
+	fmt.Fprintf(mac, "%d\n%s\n%s\n%s\n%d", issued, method, filters.Encode(), changes, affected)
+	return hex.EncodeToString(mac.Sum(nil))
+}
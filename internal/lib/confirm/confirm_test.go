@@ -0,0 +1,78 @@
+package confirm
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestValid(t *testing.T) {
+	c, err := New("secret", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := New("other secret", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filters := url.Values{"gender": {"male"}}
+	changes := []byte(`{"age":30}`)
+	token := c.Token("PATCH", filters, changes, 5)
+
+	// tokenAt signs the request as if the token was issued at the time.
+	tokenAt := func(issued time.Time) string {
+		return strconv.FormatInt(issued.Unix(), 10) + "." + c.sign(issued.Unix(), "PATCH", filters, changes, 5)
+	}
+
+	tests := []struct {
+		name     string
+		c        *Confirmer
+		token    string
+		method   string
+		filters  url.Values
+		changes  []byte
+		affected int
+		want     bool
+	}{
+		{"same request", c, token, "PATCH", filters, changes, 5, true},
+		{"other method", c, token, "DELETE", filters, changes, 5, false},
+		{"other filters", c, token, "PATCH", url.Values{"gender": {"female"}}, changes, 5, false},
+		{"other changes", c, token, "PATCH", filters, []byte(`{"age":31}`), 5, false},
+		{"other count", c, token, "PATCH", filters, changes, 6, false},
+		{"other secret", other, token, "PATCH", filters, changes, 5, false},
+		{"within ttl", c, tokenAt(time.Now().Add(-30 * time.Second)), "PATCH", filters, changes, 5, true},
+		{"expired", c, tokenAt(time.Now().Add(-2 * time.Minute)), "PATCH", filters, changes, 5, false},
+		{"issued in the future", c, tokenAt(time.Now().Add(time.Minute)), "PATCH", filters, changes, 5, false},
+		{"no signature", c, strconv.FormatInt(time.Now().Unix(), 10), "PATCH", filters, changes, 5, false},
+		{"malformed time", c, "now." + c.sign(0, "PATCH", filters, changes, 5), "PATCH", filters, changes, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.Valid(tt.token, tt.method, tt.filters, tt.changes, tt.affected); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRandomSecret(t *testing.T) {
+	a, err := New("", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New("", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := a.Token("DELETE", nil, nil, 1)
+	if !a.Valid(token, "DELETE", nil, nil, 1) {
+		t.Error("token is not valid for its confirmer")
+	}
+	if b.Valid(token, "DELETE", nil, nil, 1) {
+		t.Error("token is valid for a confirmer with another random secret")
+	}
+}
@@ -0,0 +1,292 @@
+package csvimport
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"people-service/internal/domain/models"
+	resp "people-service/internal/lib/api/response"
+	"people-service/internal/lib/names"
+	"people-service/internal/lib/validation"
+	"people-service/internal/storage"
+)
+
+// Person fields CSV columns can be mapped to.
+const (
+	FieldName        = "name"
+	FieldSurname     = "surname"
+	FieldPatronymic  = "patronymic"
+	FieldAge         = "age"
+	FieldGender      = "gender"
+	FieldNationality = "nationality"
+)
+
+var fields = map[string]bool{
+	FieldName: true, FieldSurname: true, FieldPatronymic: true,
+	FieldAge: true, FieldGender: true, FieldNationality: true,
+}
+
+// Mapping maps CSV header names to person fields.
+type Mapping map[string]string
+
+// ParseMapping reads a mapping written as "First Name:name,Last Name:surname".
+func ParseMapping(value string) (Mapping, error) {
+	m := make(Mapping)
+	for _, pair := range strings.Split(value, ",") {
+		column, field, found := strings.Cut(pair, ":")
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !found || !fields[field] {
+			return nil, fmt.Errorf("invalid column mapping %q", pair)
+		}
+		m[strings.TrimSpace(column)] = field
+	}
+	return m, nil
+}
+
+// row mirrors the validation rules of save.Request and update.Request.
+type row struct {
+	Name        string `json:"name" validate:"required,person_name"`
+	Surname     string `json:"surname" validate:"required,person_name"`
+	Patronymic  string `json:"patronymic" validate:"omitempty,person_name"`
+	Age         int    `json:"age" validate:"person_age"`
+	Gender      string `json:"gender" validate:"omitempty,gender"`
+	Nationality string `json:"nationality" validate:"omitempty,person_nationality"`
+}
+
+// Rejected is a CSV record that was not imported.
+type Rejected struct {
+	Line   int
+	Record []string
+	Reason string
+}
+
+type Summary struct {
+	Total    int
+	Ids      []int
+	Header   []string
+	Rejected []Rejected
+}
+
+type PeopleSaver interface {
+	SavePeople(ctx context.Context, people []models.Person, atomic bool) ([]storage.SaveResult, error)
+}
+
+type Enricher interface {
+	Enrich(ctx context.Context, people []models.Person)
+}
+
+type Importer struct {
+	peopleSaver PeopleSaver
+	enricher    Enricher
+}
+
+func New(peopleSaver PeopleSaver, enricher Enricher) *Importer {
+	return &Importer{peopleSaver: peopleSaver, enricher: enricher}
+}
+
+// Import reads people from CSV with a header row and stores the valid ones.
+// Columns are mapped with mapping; without one, columns named after person
+// fields are used. Missing attributes are looked up upstream when enrich is
+// set. Records that are invalid or cannot be stored are reported as rejected.
+func (im *Importer) Import(ctx context.Context, r io.Reader, mapping Mapping, enrich bool) (Summary, error) {
+	const op = "lib.csvimport.Import"
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return Summary{}, fmt.Errorf("%s: empty file", op)
+	}
+	if err != nil {
+		return Summary{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	columns, err := resolveColumns(header, mapping)
+	if err != nil {
+		return Summary{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	summary := Summary{Header: header}
+
+	var people []models.Person
+	var records []Rejected
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		summary.Total++
+		if err != nil {
+			summary.Rejected = append(summary.Rejected, Rejected{Line: line, Record: record, Reason: err.Error()})
+			continue
+		}
+
+		person, err := toPerson(record, columns)
+		if err == nil {
+			err = validation.Struct(row{
+				Name:        person.Name,
+				Surname:     person.Surname,
+				Patronymic:  person.Patronymic,
+				Age:         person.Age,
+				Gender:      person.Gender,
+				Nationality: person.Nationality,
+			})
+		}
+		if err != nil {
+			summary.Rejected = append(summary.Rejected, Rejected{Line: line, Record: record, Reason: reason(err)})
+			continue
+		}
+
+		people = append(people, person)
+		records = append(records, Rejected{Line: line, Record: record})
+	}
+
+	if len(people) == 0 {
+		return summary, nil
+	}
+
+	if enrich {
+		im.enricher.Enrich(ctx, people)
+	}
+
+	results, err := im.peopleSaver.SavePeople(ctx, people, false)
+	if err != nil {
+		return Summary{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i, res := range results {
+		if res.Err != nil {
+			rejected := records[i]
+			rejected.Reason = saveReason(res.Err)
+			summary.Rejected = append(summary.Rejected, rejected)
+			continue
+		}
+		summary.Ids = append(summary.Ids, res.Id)
+	}
+
+	sort.Slice(summary.Rejected, func(i, j int) bool {
+		return summary.Rejected[i].Line < summary.Rejected[j].Line
+	})
+
+	return summary, nil
+}
+
+// WriteReport writes rejected records as CSV: the line number and reason
+// followed by the original columns.
+func WriteReport(w io.Writer, summary Summary) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(append([]string{"line", "reason"}, summary.Header...)); err != nil {
+		return err
+	}
+	for _, r := range summary.Rejected {
+		if err := writer.Write(append([]string{strconv.Itoa(r.Line), r.Reason}, r.Record...)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func resolveColumns(header []string, mapping Mapping) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		field, ok := mapping[h]
+		if mapping == nil {
+			field, ok = strings.ToLower(h), fields[strings.ToLower(h)]
+		}
+		if ok {
+			columns[field] = i
+		}
+	}
+
+	for column := range mapping {
+		found := false
+		for _, h := range header {
+			found = found || strings.TrimSpace(h) == column
+		}
+		if !found {
+			return nil, fmt.Errorf("mapped column %q is not in the header", column)
+		}
+	}
+
+	if _, ok := columns[FieldName]; !ok {
+		return nil, errors.New("no column is mapped to name")
+	}
+	if _, ok := columns[FieldSurname]; !ok {
+		return nil, errors.New("no column is mapped to surname")
+	}
+
+	return columns, nil
+}
+
+func toPerson(record []string, columns map[string]int) (models.Person, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	person := models.Person{
+		Name:        names.Normalize(value(FieldName)),
+		Surname:     names.Normalize(value(FieldSurname)),
+		Patronymic:  names.Normalize(value(FieldPatronymic)),
+		Gender:      value(FieldGender),
+		Nationality: value(FieldNationality),
+	}
+
+	if v := value(FieldAge); v != "" {
+		age, err := strconv.Atoi(v)
+		if err != nil || age < 0 {
+			return models.Person{}, errors.New("field age is not valid")
+		}
+		person.Age = age
+	}
+
+	if person.Age != 0 {
+		person.AgeSource = models.SourceManual
+	}
+	if person.Gender != "" {
+		person.GenderSource = models.SourceManual
+	}
+	if person.Nationality != "" {
+		person.NationalitySource = models.SourceManual
+	}
+
+	return person, nil
+}
+
+func reason(err error) string {
+	var validateErrs validator.ValidationErrors
+	if errors.As(err, &validateErrs) {
+		return resp.ValidationError(validateErrs).Error
+	}
+	return err.Error()
+}
+
+func saveReason(err error) string {
+	var existsErr *storage.ExistsError
+	switch {
+	case errors.As(err, &existsErr):
+		return fmt.Sprintf("person already exists: id %d", existsErr.Id)
+	case errors.Is(err, storage.ErrPersonExists):
+		return "person already exists"
+	default:
+		return "failed to add person"
+	}
+}
@@ -0,0 +1,57 @@
+package csvimport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Reports keeps error reports of recent imports in memory for download.
+type Reports struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	reports map[string]report
+}
+
+type report struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewReports(ttl time.Duration) *Reports {
+	return &Reports{ttl: ttl, reports: make(map[string]report)}
+}
+
+// Put stores the report and returns its id.
+func (r *Reports) Put(data []byte) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(buf)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, v := range r.reports {
+		if now.After(v.expiresAt) {
+			delete(r.reports, k)
+		}
+	}
+	r.reports[id] = report{data: data, expiresAt: now.Add(r.ttl)}
+
+	return id, nil
+}
+
+func (r *Reports) Get(id string) ([]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep, ok := r.reports[id]
+	if !ok || time.Now().After(rep.expiresAt) {
+		return nil, false
+	}
+	return rep.data, true
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"people-service/internal/domain/models"
+)
+
+// Column is an exported attribute of a person. Value returns a string, an int
+// or nil for an absent value.
+type Column struct {
+	Name  string
+	Value func(p models.Person) interface{}
+}
+
+var columns = []Column{
+	{"id", func(p models.Person) interface{} { return p.Id }},
+	{"name", func(p models.Person) interface{} { return p.Name }},
+	{"surname", func(p models.Person) interface{} { return p.Surname }},
+	{"patronymic", func(p models.Person) interface{} { return p.Patronymic }},
+	{"age", func(p models.Person) interface{} { return p.Age }},
+	{"gender", func(p models.Person) interface{} { return p.Gender }},
+	{"nationality", func(p models.Person) interface{} { return p.Nationality }},
+	{"name_latin", func(p models.Person) interface{} { return p.NameLatin }},
+	{"surname_latin", func(p models.Person) interface{} { return p.SurnameLatin }},
+	{"patronymic_latin", func(p models.Person) interface{} { return p.PatronymicLatin }},
+	{"age_source", func(p models.Person) interface{} { return p.AgeSource }},
+	{"gender_source", func(p models.Person) interface{} { return p.GenderSource }},
+	{"nationality_source", func(p models.Person) interface{} { return p.NationalitySource }},
+	{"enriched_at", func(p models.Person) interface{} {
+		if p.EnrichedAt == nil {
+			return nil
+		}
+		return p.EnrichedAt.UTC().Format(time.RFC3339)
+	}},
+	{"created_at", func(p models.Person) interface{} { return p.CreatedAt.UTC().Format(time.RFC3339) }},
+	{"updated_at", func(p models.Person) interface{} { return p.UpdatedAt.UTC().Format(time.RFC3339) }},
+}
+
+// AllColumns returns every column in the default order.
+func AllColumns() []Column {
+	return columns
+}
+
+// Columns returns the columns listed in a comma separated value, in the
+// listed order, or all of them for an empty value.
+func Columns(value string) ([]Column, error) {
+	if value == "" {
+		return columns, nil
+	}
+
+	byName := make(map[string]Column, len(columns))
+	for _, c := range columns {
+		byName[c.Name] = c
+	}
+
+	var selected []Column
+	for _, name := range strings.Split(value, ",") {
+		c, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		selected = append(selected, c)
+	}
+
+	return selected, nil
+}
+
+func format(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
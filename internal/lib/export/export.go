@@ -0,0 +1,152 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"people-service/internal/domain/models"
+)
+
+// Supported export formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+// Writer streams people in an export format. Close must be called to flush
+// the output; it does not close the underlying writer.
+type Writer interface {
+	Write(p models.Person) error
+	Close() error
+}
+
+// ContentType returns the media type of the format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return ""
+}
+
+// NewWriter creates a writer of the format with the given columns.
+func NewWriter(format string, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w), columns: columns}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(p models.Person) error {
+	record := make([]string, len(cw.columns))
+	for i, c := range cw.columns {
+		record[i] = format(c.Value(p))
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	columns []Column
+}
+
+func (nw *ndjsonWriter) Write(p models.Person) error {
+	return nw.enc.Encode(Record(p, nw.columns))
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+type record struct {
+	columns []Column
+	person  models.Person
+}
+
+// Record projects the person onto columns. It marshals to a JSON object with
+// keys in column order.
+func Record(p models.Person, columns []Column) json.Marshaler {
+	return record{columns: columns, person: p}
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for i, c := range r.columns {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, _ := json.Marshal(c.Name)
+		value, err := json.Marshal(c.Value(r.person))
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	return append(buf, '}'), nil
+}
+
+// MarshalXML writes the record as one child element per column, leaving out
+// absent values.
+func (r record) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, c := range r.columns {
+		v := c.Value(r.person)
+		if v == nil {
+			continue
+		}
+		if err := e.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: c.Name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// Records projects every person onto columns.
+func Records(people []models.Person, columns []Column) []json.Marshaler {
+	records := make([]json.Marshaler, len(people))
+	for i, p := range people {
+		records[i] = Record(p, columns)
+	}
+	return records
+}
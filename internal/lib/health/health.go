@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrShuttingDown = errors.New("shutting down")
+
+// CheckFunc reports whether a dependency of the service is usable.
+type CheckFunc func(ctx context.Context) error
+
+type Check struct {
+	Name  string
+	Check CheckFunc
+}
+
+// Result is the outcome of one check; Err is nil if it passed.
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Checker tells whether the service is ready to serve requests.
+type Checker struct {
+	checks       []Check
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(checks ...Check) *Checker {
+	return &Checker{checks: checks}
+}
+
+// Shutdown makes the service report not ready from now on, so that the
+// orchestrator stops routing requests to it while it drains.
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready runs the checks concurrently and returns their results in the order
+// they were given. The service is ready if it is not shutting down and every
+// check passed.
+func (c *Checker) Ready(ctx context.Context) (bool, []Result) {
+	if c.shuttingDown.Load() {
+		return false, []Result{{Name: "shutdown", Err: ErrShuttingDown}}
+	}
+
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = Result{Name: check.Name, Err: check.Check(ctx)}
+		}(i, check)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, r := range results {
+		if r.Err != nil {
+			ready = false
+		}
+	}
+	return ready, results
+}
+
+// Cached reuses the outcome of check, passed or failed, for ttl, sparing
+// slow or rate limited dependencies a request on every probe.
+func Cached(check CheckFunc, ttl time.Duration) CheckFunc {
+	var (
+		mu      sync.Mutex
+		checked time.Time
+		last    error
+	)
+
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !checked.IsZero() && time.Since(checked) < ttl {
+			return last
+		}
+		last = check(ctx)
+		checked = time.Now()
+		return last
+	}
+}
+
+// Reachable checks that url answers HTTP requests. Any status below 500
+// counts, since upstreams may reject a request carrying no parameters.
+func Reachable(client *http.Client, url string) CheckFunc {
+	return func(ctx context.Context) error {
+		const op = "health.Reachable"
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s: %s answered %s", op, url, res.Status)
+		}
+		return nil
+	}
+}
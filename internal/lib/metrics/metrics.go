@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "people"
+
+// Outcomes of enrichment cache lookups.
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+var (
+	// HTTPRequests and HTTPRequestDuration are labelled by the chi route
+	// pattern rather than the path, so that ids do not make a series each.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to serve HTTP requests, by method, route pattern and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time taken by storage calls, by call.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query"})
+
+	EnrichCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrich_calls_total",
+		Help:      "Requests made to enrichment providers.",
+	}, []string{"provider"})
+
+	EnrichErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrich_errors_total",
+		Help:      "Requests to enrichment providers that failed or were answered with an error status.",
+	}, []string{"provider"})
+
+	EnrichDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "enrich_duration_seconds",
+		Help:      "Time taken by requests to enrichment providers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	// EnrichCache counts lookups of names in the enrichment cache; the hit
+	// ratio is the rate of hits over the rate of all lookups.
+	EnrichCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrich_cache_lookups_total",
+		Help:      "Lookups of names in the enrichment cache, by provider and result.",
+	}, []string{"provider", "result"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveDBPool exports the connection pool statistics stats reports,
+// read whenever metrics are scraped.
+func ObserveDBPool(stats func() sql.DBStats) {
+	gauge := func(name, help string, value func(sql.DBStats) float64) {
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(stats()) })
+	}
+	counter := func(name, help string, value func(sql.DBStats) float64) {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(stats()) })
+	}
+
+	gauge("max_open_connections", "Maximum number of open connections, 0 for no limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("open_connections", "Open connections, in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("in_use_connections", "Connections in use.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("idle_connections", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("wait_count_total", "Connections waited for.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("wait_duration_seconds_total", "Time spent waiting for connections.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("max_idle_closed_total", "Connections closed because the idle pool was full.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("max_idle_time_closed_total", "Connections closed for being idle too long.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) })
+	counter("max_lifetime_closed_total", "Connections closed for reaching their maximum lifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
+
+// Transport records the requests made through next to the enrichment
+// provider. Responses with a 4xx or 5xx status count as errors.
+func Transport(provider string, next http.RoundTripper) http.RoundTripper {
+	return roundTripper{provider: provider, next: next}
+}
+
+type roundTripper struct {
+	provider string
+	next     http.RoundTripper
+}
+
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	timer := prometheus.NewTimer(EnrichDuration.WithLabelValues(t.provider))
+	defer timer.ObserveDuration()
+
+	EnrichCalls.WithLabelValues(t.provider).Inc()
+	res, err := t.next.RoundTrip(req)
+	if err != nil || res.StatusCode >= http.StatusBadRequest {
+		EnrichErrors.WithLabelValues(t.provider).Inc()
+	}
+	return res, err
+}
@@ -0,0 +1,20 @@
+package names
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns the canonical spelling of a name: surrounding space is
+// trimmed, inner runs of space collapse to one, the value is composed to
+// Unicode NFC and title-cased, so " IVAN ", "ivan" and "Ivan" are all "Ivan".
+func Normalize(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	value = norm.NFC.String(value)
+
+	// Casers keep state and are not safe for concurrent use.
+	return cases.Title(language.Und).String(value)
+}
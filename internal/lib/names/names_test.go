@@ -0,0 +1,26 @@
+package names
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Ivan", "Ivan"},
+		{"ivan", "Ivan"},
+		{" IVAN ", "Ivan"},
+		{"anna   maria", "Anna Maria"},
+		{"анна-мария", "Анна-Мария"},
+		{"о'брайен", "О'брайен"},
+		{"émile", "Émile"},
+		{"e\u0301mile", "Émile"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.value); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package queryparam
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"people-service/internal/domain/models"
+)
+
+// Cursor points at a row of a sorted listing. Values hold the row's values of
+// the sort columns in sort order; the last one is always the id.
+type Cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// NewCursor builds the cursor of the person for the given sort.
+func NewCursor(sort []SortField, person models.Person) Cursor {
+	values := make([]string, 0, len(sort))
+	for _, f := range sort {
+		values = append(values, columnValue(f.Column, person))
+	}
+	return Cursor{Sort: sortSignature(sort), Values: values}
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string, sort []SortField) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	if c.Sort != sortSignature(sort) || len(c.Values) != len(sort) {
+		return nil, errors.New("cursor does not match the sort order")
+	}
+	for i, f := range sort {
+		if !validColumnValue(f.Column, c.Values[i]) {
+			return nil, errors.New("malformed cursor")
+		}
+	}
+
+	return &c, nil
+}
+
+func sortSignature(sort []SortField) string {
+	parts := make([]string, 0, len(sort))
+	for _, f := range sort {
+		if f.Desc {
+			parts = append(parts, "-"+f.Column)
+		} else {
+			parts = append(parts, f.Column)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// validColumnValue reports whether value, taken from a cursor, has the type of
+// the column: an integer for id and age, an RFC 3339 time for timestamps.
+func validColumnValue(column, value string) bool {
+	var err error
+	switch column {
+	case "id", "age":
+		_, err = strconv.Atoi(value)
+	case "created_at", "updated_at":
+		_, err = time.Parse(time.RFC3339Nano, value)
+	}
+	return err == nil
+}
+
+func columnValue(column string, p models.Person) string {
+	switch column {
+	case "id":
+		return strconv.Itoa(p.Id)
+	case "name":
+		return p.Name
+	case "surname":
+		return p.Surname
+	case "patronymic":
+		return p.Patronymic
+	case "age":
+		return strconv.Itoa(p.Age)
+	case "gender":
+		return p.Gender
+	case "nationality":
+		return p.Nationality
+	case "created_at":
+		return p.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "updated_at":
+		return p.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
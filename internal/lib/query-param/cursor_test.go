@@ -0,0 +1,62 @@
+package queryparam
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"people-service/internal/domain/models"
+)
+
+func encode(t *testing.T, c Cursor) string {
+	t.Helper()
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestDecodeCursor(t *testing.T) {
+	sort := []SortField{{Column: "created_at", Desc: true}, {Column: "age"}, {Column: "id"}}
+	signature := sortSignature(sort)
+
+	person := models.Person{Id: 7, Age: 30, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"round trip", NewCursor(sort, person).Encode(), false},
+		{"not base64", "!!!", true},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("cursor")), true},
+		{"other sort", encode(t, Cursor{Sort: "id", Values: []string{"7"}}), true},
+		{"too few values", encode(t, Cursor{Sort: signature, Values: []string{"7"}}), true},
+		{"id not an integer", encode(t, Cursor{Sort: signature, Values: []string{"2024-01-02T03:04:05Z", "30", "x"}}), true},
+		{"age not an integer", encode(t, Cursor{Sort: signature, Values: []string{"2024-01-02T03:04:05Z", "thirty", "7"}}), true},
+		{"time not RFC 3339", encode(t, Cursor{Sort: signature, Values: []string{"2024-01-02", "30", "7"}}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := decodeCursor(tt.value, sort)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got cursor %+v, want an error", c)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []string{"2024-01-02T03:04:05.000000006Z", "30", "7"}
+			for i := range want {
+				if c.Values[i] != want[i] {
+					t.Errorf("value %d: got %q, want %q", i, c.Values[i], want[i])
+				}
+			}
+		})
+	}
+}
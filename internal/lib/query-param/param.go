@@ -1,13 +1,68 @@
-package queryparam
-
-type Params struct {
-	Id          string
-	Name        string
-	Surname     string
-	Patronymic  string
-	Age         string
-	Gender      string
-	Nationality string
-	Offset      string
-	Limit       string
-}
+package queryparam
+
+import "time"
+
+// Operators of string filters. A filter value is written as
+// "[not:][op:]operand", e.g. "Ivan", "prefix:Iv", "not:in:male,female".
+const (
+	OpEq       = "eq"
+	OpIn       = "in"
+	OpPrefix   = "prefix"
+	OpContains = "contains"
+	OpLike     = "like"
+)
+
+const opNot = "not"
+
+type StringFilter struct {
+	Op     string
+	Values []string
+	Negate bool
+}
+
+// SortField orders results by a storage column, ascending unless Desc.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+type Params struct {
+	Ids         []int
+	Name        *StringFilter
+	Surname     *StringFilter
+	Patronymic  *StringFilter
+	Age         *int
+	AgeMin      *int
+	AgeMax      *int
+	Gender      *StringFilter
+	Nationality *StringFilter
+
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+
+	// Sort always ends with the id column so that the order is stable.
+	Sort []SortField
+
+	// After and Before are keyset cursors; at most one of them is set.
+	After  *Cursor
+	Before *Cursor
+
+	Offset uint
+	Limit  uint
+
+	// Fields are the columns to read besides id and the sort columns; all
+	// of them when empty.
+	Fields []string
+}
+
+// Filtered reports whether any filter is set.
+func (p Params) Filtered() bool {
+	return len(p.Ids) > 0 ||
+		p.Name != nil || p.Surname != nil || p.Patronymic != nil ||
+		p.Age != nil || p.AgeMin != nil || p.AgeMax != nil ||
+		p.Gender != nil || p.Nationality != nil ||
+		p.CreatedAfter != nil || p.CreatedBefore != nil ||
+		p.UpdatedAfter != nil || p.UpdatedBefore != nil
+}
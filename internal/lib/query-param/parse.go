@@ -0,0 +1,302 @@
+package queryparam
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"people-service/internal/lib/names"
+	"people-service/internal/lib/routing"
+)
+
+// dateLayout is accepted by date range filters along with RFC 3339.
+const dateLayout = "2006-01-02"
+
+var ErrInvalidParam = errors.New("invalid query parameter")
+
+// pageParams choose a page of a listing rather than the people in it.
+var pageParams = []string{
+	routing.SortParam,
+	routing.AfterParam,
+	routing.BeforeParam,
+	routing.OffsetParam,
+	routing.LimitParam,
+	routing.SizeParam,
+}
+
+// sortColumns maps sortable query names to storage columns.
+var sortColumns = map[string]string{
+	routing.IdParam:          "id",
+	routing.NameParam:        "name",
+	routing.SurnameParam:     "surname",
+	routing.PatronymicParam:  "patronymic",
+	routing.AgeParam:         "age",
+	routing.GenderParam:      "gender",
+	routing.NationalityParam: "nationality",
+	routing.CreatedAtParam:   "created_at",
+	routing.UpdatedAtParam:   "updated_at",
+}
+
+var (
+	nameOps = map[string]bool{OpEq: true, OpIn: true, OpPrefix: true, OpContains: true, OpLike: true}
+	setOps  = map[string]bool{OpEq: true, OpIn: true}
+)
+
+// Parse reads person filters and paging from the query string.
+// Malformed values are reported with an error wrapping ErrInvalidParam.
+func Parse(q url.Values) (Params, error) {
+	var p Params
+	var err error
+
+	if v := q.Get(routing.IdParam); v != "" {
+		if p.Ids, err = parseIds(v); err != nil {
+			return Params{}, invalid(routing.IdParam, v, err)
+		}
+	}
+
+	strFilters := []struct {
+		param  string
+		ops    map[string]bool
+		target **StringFilter
+	}{
+		{routing.NameParam, nameOps, &p.Name},
+		{routing.SurnameParam, nameOps, &p.Surname},
+		{routing.PatronymicParam, nameOps, &p.Patronymic},
+		{routing.GenderParam, setOps, &p.Gender},
+		{routing.NationalityParam, setOps, &p.Nationality},
+	}
+	for _, f := range strFilters {
+		v := q.Get(f.param)
+		if v == "" {
+			continue
+		}
+		if *f.target, err = parseStringFilter(v, f.ops); err != nil {
+			return Params{}, invalid(f.param, v, err)
+		}
+	}
+
+	// Names are stored normalized, so exact operands are normalized too.
+	for _, f := range []*StringFilter{p.Name, p.Surname, p.Patronymic} {
+		if f != nil && (f.Op == OpEq || f.Op == OpIn) {
+			for i, v := range f.Values {
+				f.Values[i] = names.Normalize(v)
+			}
+		}
+	}
+
+	intFilters := []struct {
+		param  string
+		target **int
+	}{
+		{routing.AgeParam, &p.Age},
+		{routing.AgeMinParam, &p.AgeMin},
+		{routing.AgeMaxParam, &p.AgeMax},
+	}
+	for _, f := range intFilters {
+		v := q.Get(f.param)
+		if v == "" {
+			continue
+		}
+		n, err := parseNonNegative(v)
+		if err != nil {
+			return Params{}, invalid(f.param, v, err)
+		}
+		*f.target = &n
+	}
+	if p.AgeMin != nil && p.AgeMax != nil && *p.AgeMin > *p.AgeMax {
+		return Params{}, fmt.Errorf("%w: %s is greater than %s", ErrInvalidParam, routing.AgeMinParam, routing.AgeMaxParam)
+	}
+
+	timeFilters := []struct {
+		param  string
+		target **time.Time
+	}{
+		{routing.CreatedAfterParam, &p.CreatedAfter},
+		{routing.CreatedBeforeParam, &p.CreatedBefore},
+		{routing.UpdatedAfterParam, &p.UpdatedAfter},
+		{routing.UpdatedBeforeParam, &p.UpdatedBefore},
+	}
+	for _, f := range timeFilters {
+		v := q.Get(f.param)
+		if v == "" {
+			continue
+		}
+		t, err := parseTime(v)
+		if err != nil {
+			return Params{}, invalid(f.param, v, errors.New("expected RFC 3339 time or YYYY-MM-DD date"))
+		}
+		*f.target = &t
+	}
+
+	if p.Sort, err = parseSort(q.Get(routing.SortParam)); err != nil {
+		return Params{}, invalid(routing.SortParam, q.Get(routing.SortParam), err)
+	}
+
+	cursorParams := []struct {
+		param  string
+		target **Cursor
+	}{
+		{routing.AfterParam, &p.After},
+		{routing.BeforeParam, &p.Before},
+	}
+	for _, f := range cursorParams {
+		v := q.Get(f.param)
+		if v == "" {
+			continue
+		}
+		if *f.target, err = decodeCursor(v, p.Sort); err != nil {
+			return Params{}, invalid(f.param, v, err)
+		}
+	}
+	if p.After != nil && p.Before != nil {
+		return Params{}, fmt.Errorf("%w: %s and %s cannot be combined", ErrInvalidParam, routing.AfterParam, routing.BeforeParam)
+	}
+
+	// size is the page size; limit is kept as its older alias.
+	pageParams := []struct {
+		param  string
+		target *uint
+	}{
+		{routing.OffsetParam, &p.Offset},
+		{routing.LimitParam, &p.Limit},
+		{routing.SizeParam, &p.Limit},
+	}
+	for _, f := range pageParams {
+		v := q.Get(f.param)
+		if v == "" {
+			continue
+		}
+		n, err := parseNonNegative(v)
+		if err != nil {
+			return Params{}, invalid(f.param, v, err)
+		}
+		*f.target = uint(n)
+	}
+	if p.Offset > 0 && (p.After != nil || p.Before != nil) {
+		return Params{}, fmt.Errorf("%w: %s cannot be combined with a cursor", ErrInvalidParam, routing.OffsetParam)
+	}
+
+	return p, nil
+}
+
+// ParseFilters reads person filters for operations on every matching person.
+// Paging and sort parameters are rejected, and so is a query without filters.
+func ParseFilters(q url.Values) (Params, error) {
+	for _, param := range pageParams {
+		if q.Has(param) {
+			return Params{}, fmt.Errorf("%w: %s is not supported here", ErrInvalidParam, param)
+		}
+	}
+
+	p, err := Parse(q)
+	if err != nil {
+		return Params{}, err
+	}
+	if !p.Filtered() {
+		return Params{}, fmt.Errorf("%w: at least one filter is required", ErrInvalidParam)
+	}
+
+	return p, nil
+}
+
+func parseStringFilter(value string, ops map[string]bool) (*StringFilter, error) {
+	f := StringFilter{Op: OpEq}
+
+	rest := value
+	if after, found := strings.CutPrefix(rest, opNot+":"); found {
+		f.Negate = true
+		rest = after
+	}
+	if op, operand, found := strings.Cut(rest, ":"); found && nameOps[op] {
+		if !ops[op] {
+			return nil, fmt.Errorf("operator %q is not supported", op)
+		}
+		f.Op = op
+		rest = operand
+	}
+
+	if f.Op == OpIn {
+		for _, v := range strings.Split(rest, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				f.Values = append(f.Values, v)
+			}
+		}
+	} else if rest != "" {
+		f.Values = []string{rest}
+	}
+
+	if len(f.Values) == 0 {
+		return nil, errors.New("empty operand")
+	}
+
+	return &f, nil
+}
+
+// parseSort reads a comma separated list of sortable names, each optionally
+// prefixed with "-" for descending order, e.g. "-age,surname".
+func parseSort(value string) ([]SortField, error) {
+	var fields []SortField
+	seen := make(map[string]bool)
+
+	if value != "" {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			desc := strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(strings.TrimPrefix(name, "-"), "+")
+
+			column, ok := sortColumns[name]
+			if !ok {
+				return nil, fmt.Errorf("cannot sort by %q", name)
+			}
+			if seen[column] {
+				return nil, fmt.Errorf("%q is listed more than once", name)
+			}
+			seen[column] = true
+
+			fields = append(fields, SortField{Column: column, Desc: desc})
+		}
+	}
+
+	if !seen["id"] {
+		fields = append(fields, SortField{Column: "id"})
+	}
+
+	return fields, nil
+}
+
+func parseIds(value string) ([]int, error) {
+	value = strings.TrimPrefix(value, OpIn+":")
+
+	var ids []int
+	for _, v := range strings.Split(value, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, errors.New("expected integer or in:list of integers")
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func parseNonNegative(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, errors.New("expected non-negative integer")
+	}
+	return n, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(dateLayout, value)
+}
+
+func invalid(param, value string, err error) error {
+	return fmt.Errorf("%w %s=%q: %s", ErrInvalidParam, param, value, err)
+}
@@ -0,0 +1,87 @@
+package queryparam
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestParse(t *testing.T) {
+	byId := []SortField{{Column: "id"}}
+
+	tests := []struct {
+		query   string
+		want    Params
+		wantErr bool
+	}{
+		{"", Params{Sort: byId}, false},
+		{"id=3", Params{Ids: []int{3}, Sort: byId}, false},
+		{"id=in:1,2", Params{Ids: []int{1, 2}, Sort: byId}, false},
+		{"id=one", Params{}, true},
+		{"name=Ivan", Params{Name: &StringFilter{Op: OpEq, Values: []string{"Ivan"}}, Sort: byId}, false},
+		{"surname=prefix:Iv", Params{Surname: &StringFilter{Op: OpPrefix, Values: []string{"Iv"}}, Sort: byId}, false},
+		{"patronymic=not:contains:ich", Params{Patronymic: &StringFilter{Op: OpContains, Values: []string{"ich"}, Negate: true}, Sort: byId}, false},
+		{"name=like:I%25n", Params{Name: &StringFilter{Op: OpLike, Values: []string{"I%n"}}, Sort: byId}, false},
+		{"gender=in:male,,female", Params{Gender: &StringFilter{Op: OpIn, Values: []string{"male", "female"}}, Sort: byId}, false},
+		{"gender=prefix:ma", Params{}, true},
+		{"nation=in:", Params{}, true},
+		{"age=30", Params{Age: intPtr(30), Sort: byId}, false},
+		{"age_min=20&age_max=40", Params{AgeMin: intPtr(20), AgeMax: intPtr(40), Sort: byId}, false},
+		{"age_min=40&age_max=20", Params{}, true},
+		{"age=-1", Params{}, true},
+		{"created_after=not-a-date", Params{}, true},
+		{"sort=-age,surname", Params{Sort: []SortField{{Column: "age", Desc: true}, {Column: "surname"}, {Column: "id"}}}, false},
+		{"sort=age,age", Params{}, true},
+		{"sort=height", Params{}, true},
+		{"size=10&offset=20", Params{Limit: 10, Offset: 20, Sort: byId}, false},
+		{"limit=x", Params{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Parse(q)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidParam) {
+					t.Fatalf("got error %v, want one wrapping ErrInvalidParam", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimes(t *testing.T) {
+	q := url.Values{
+		"created_after":  {"2024-01-02"},
+		"updated_before": {"2024-01-02T03:04:05+03:00"},
+	}
+
+	p, err := Parse(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); p.CreatedAfter == nil || !p.CreatedAfter.Equal(want) {
+		t.Errorf("got created_after %v, want %v", p.CreatedAfter, want)
+	}
+	if want := time.Date(2024, 1, 2, 0, 4, 5, 0, time.UTC); p.UpdatedBefore == nil || !p.UpdatedBefore.Equal(want) {
+		t.Errorf("got updated_before %v, want %v", p.UpdatedBefore, want)
+	}
+}
@@ -0,0 +1,120 @@
+package rbac
+
+import (
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/auth"
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/lib/routing"
+)
+
+// Roles needed for enrichment-derived attributes: age, gender and
+// nationality not set by hand, which came from the data-prep services or
+// were stored before sources were tracked.
+const (
+	SeeEnriched      = auth.RoleEditor
+	OverrideEnriched = auth.RoleAdmin
+)
+
+// Attributes that may be enrichment-derived, named as in JSON.
+const (
+	FieldAge         = "age"
+	FieldGender      = "gender"
+	FieldNationality = "nationality"
+)
+
+// Redact clears the enrichment-derived attributes of the person, along with
+// their provenance, if the role may not see them.
+func Redact(role auth.Role, p *models.Person) {
+	if role.Allows(SeeEnriched) {
+		return
+	}
+
+	if enriched(p.AgeSource, p.Age != 0) {
+		p.Age, p.AgeSource = 0, ""
+	}
+	if enriched(p.GenderSource, p.Gender != "") {
+		p.Gender, p.GenderSource = "", ""
+	}
+	if enriched(p.NationalitySource, p.Nationality != "") {
+		p.Nationality, p.NationalitySource = "", ""
+	}
+	p.EnrichedAt = nil
+}
+
+func RedactPeople(role auth.Role, people []models.Person) {
+	for i := range people {
+		Redact(role, &people[i])
+	}
+}
+
+// Filters returns the query parameters that filter or sort by attributes that
+// may be enrichment-derived, if the role may not see them: matching or
+// ordering people by a redacted value would reveal it.
+func Filters(role auth.Role, p queryparam.Params) []string {
+	if role.Allows(SeeEnriched) {
+		return nil
+	}
+
+	var params []string
+	if p.Age != nil {
+		params = append(params, routing.AgeParam)
+	}
+	if p.AgeMin != nil {
+		params = append(params, routing.AgeMinParam)
+	}
+	if p.AgeMax != nil {
+		params = append(params, routing.AgeMaxParam)
+	}
+	if p.Gender != nil {
+		params = append(params, routing.GenderParam)
+	}
+	if p.Nationality != nil {
+		params = append(params, routing.NationalityParam)
+	}
+	for _, f := range p.Sort {
+		if f.Column == FieldAge || f.Column == FieldGender || f.Column == FieldNationality {
+			params = append(params, routing.SortParam)
+			break
+		}
+	}
+
+	return params
+}
+
+// Overrides returns the enrichment-derived attributes of current that the
+// update changes, if the role may not override them. Attributes the update
+// leaves out or repeats keep their enrichment-derived values and sources.
+func Overrides(role auth.Role, current models.Person, update *models.Person) []string {
+	if role.Allows(OverrideEnriched) {
+		return nil
+	}
+
+	var fields []string
+	if enriched(current.AgeSource, current.Age != 0) {
+		if update.Age == 0 || update.Age == current.Age {
+			update.Age, update.AgeSource = current.Age, current.AgeSource
+		} else {
+			fields = append(fields, FieldAge)
+		}
+	}
+	if enriched(current.GenderSource, current.Gender != "") {
+		if update.Gender == "" || update.Gender == current.Gender {
+			update.Gender, update.GenderSource = current.Gender, current.GenderSource
+		} else {
+			fields = append(fields, FieldGender)
+		}
+	}
+	if enriched(current.NationalitySource, current.Nationality != "") {
+		if update.Nationality == "" || update.Nationality == current.Nationality {
+			update.Nationality, update.NationalitySource = current.Nationality, current.NationalitySource
+		} else {
+			fields = append(fields, FieldNationality)
+		}
+	}
+
+	return fields
+}
+
+func enriched(source string, set bool) bool {
+	return set && source != models.SourceManual
+}
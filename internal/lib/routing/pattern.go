@@ -0,0 +1,23 @@
+package routing
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// Pattern returns the chi route pattern the request matched, such as
+// /v1/person/{personId}, or "" if it matched none. It is complete only once
+// the router has served the request.
+func Pattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	pattern := rctx.RoutePattern()
+	if pattern != "/" {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
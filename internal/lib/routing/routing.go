@@ -1,17 +1,44 @@
-package routing
-
-const (
-	PersonIdParam = "personId"
-
-	AgeParam         = "age"
-	NameParam        = "name"
-	SurnameParam     = "surname"
-	IdParam          = "id"
-	NationalityParam = "nation"
-	GenderParam      = "gender"
-	PatronymicParam  = "patronymic"
-
-	OffsetParam = "offset"
-	LimitParam  = "limit"
-	SizeParam   = "size"
-)
+package routing
+
+const (
+	PersonIdParam = "personId"
+	ReportIdParam = "reportId"
+
+	AgeParam         = "age"
+	NameParam        = "name"
+	SurnameParam     = "surname"
+	IdParam          = "id"
+	NationalityParam = "nation"
+	GenderParam      = "gender"
+	PatronymicParam  = "patronymic"
+
+	AgeMinParam = "age_min"
+	AgeMaxParam = "age_max"
+
+	CreatedAfterParam  = "created_after"
+	CreatedBeforeParam = "created_before"
+	UpdatedAfterParam  = "updated_after"
+	UpdatedBeforeParam = "updated_before"
+
+	ThresholdParam = "threshold"
+	QueryParam     = "q"
+	BucketParam    = "bucket"
+	ModeParam      = "mode"
+	DryRunParam    = "dry_run"
+	ConfirmParam   = "confirm"
+	FormatParam    = "format"
+	ColumnsParam   = "columns"
+	FieldsParam    = "fields"
+
+	CreatedAtParam = "created_at"
+	UpdatedAtParam = "updated_at"
+
+	SortParam = "sort"
+
+	AfterParam  = "after"
+	BeforeParam = "before"
+
+	OffsetParam = "offset"
+	LimitParam  = "limit"
+	SizeParam   = "size"
+)
@@ -0,0 +1,109 @@
+package translit
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldCyrillic maps Cyrillic letters to a rough phonetic Latin form used only
+// for comparison, so that different romanizations of the same name collapse
+// to one key.
+var foldCyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "h", 'ц': "c", 'ч': "ch", 'ш': "sh", 'щ': "sh", 'ъ': "",
+	'ы': "i", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+	'і': "i", 'ї': "i", 'є': "e", 'ґ': "g", 'ў': "u",
+}
+
+// foldLatin normalizes common spelling variants of romanized names.
+// Order matters: longer sequences go first.
+var foldLatin = strings.NewReplacer(
+	"shch", "sh",
+	"sch", "sh",
+	"kh", "h",
+	"tz", "c",
+	"ts", "c",
+	"cz", "c",
+	"ph", "f",
+	"ck", "k",
+	"w", "v",
+	"x", "ks",
+	"q", "k",
+	"j", "i",
+	"y", "i",
+)
+
+// Fold returns a comparison key of the value: it is lower-cased, Cyrillic is
+// romanized, spelling variants are unified and repeated letters are collapsed,
+// so "Дмитрий", "Dmitriy", "Dmitrij" and "Dmitry" all fold to "dmitri".
+func Fold(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if s, ok := foldCyrillic[r]; ok {
+			b.WriteString(s)
+			continue
+		}
+		switch {
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-':
+			b.WriteRune(' ')
+		}
+	}
+
+	words := strings.Fields(foldLatin.Replace(b.String()))
+	for i, w := range words {
+		words[i] = collapse(w)
+	}
+
+	return strings.Join(words, " ")
+}
+
+func collapse(word string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range word {
+		if r != prev {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// iso9 maps Cyrillic letters to Latin as ISO 9:1995, also published as
+// GOST 7.79-2000 System A. Every letter has exactly one transliteration.
+var iso9 = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g̀", 'д': "d", 'е': "e",
+	'ё': "ë", 'є': "ê", 'ж': "ž", 'з': "z", 'и': "i", 'і': "ì", 'ї': "ï",
+	'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p",
+	'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ў': "ǔ", 'ф': "f", 'х': "h",
+	'ц': "c", 'ч': "č", 'ш': "š", 'щ': "ŝ", 'ъ': "ʺ", 'ы': "y", 'ь': "ʹ",
+	'э': "è", 'ю': "û", 'я': "â",
+}
+
+// Latin transliterates the Cyrillic letters of the value per ISO 9, keeping
+// their case, and leaves everything else as is: "Щукин" is "Ŝukin" and
+// "Ivan" stays "Ivan".
+func Latin(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		s, ok := iso9[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.IsUpper(r) {
+			first, rest := []rune(s)[0], []rune(s)[1:]
+			b.WriteRune(unicode.ToUpper(first))
+			b.WriteString(string(rest))
+			continue
+		}
+		b.WriteString(s)
+	}
+	return norm.NFC.String(b.String())
+}
@@ -0,0 +1,51 @@
+package translit
+
+import "testing"
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Дмитрий", "dmitri"},
+		{"Dmitriy", "dmitri"},
+		{"Dmitrij", "dmitri"},
+		{"Dmitry", "dmitri"},
+		{"Щукин", "shukin"},
+		{"Shchukin", "shukin"},
+		{"Anna-Maria  Ivanova", "ana maria ivanova"},
+		{"O'Brien", "obrien"},
+		{"Alexander", "aleksander"},
+	}
+
+	for _, tt := range tests {
+		if got := Fold(tt.value); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLatin(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Ivan", "Ivan"},
+		{"Щукин", "Ŝukin"},
+		{"Жанна", "Žanna"},
+		{"Юлия", "Ûliâ"},
+		{"Ёлкин", "Ëlkin"},
+		{"Чёрный", "Čërnyj"},
+		{"Анна-Мария", "Anna-Mariâ"},
+		{"Ґалина", "G̀alina"},
+		{"Ольга Ivanova", "Olʹga Ivanova"},
+	}
+
+	for _, tt := range tests {
+		if got := Latin(tt.value); got != tt.want {
+			t.Errorf("Latin(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"people-service/internal/domain/models"
+)
+
+// Tags for person fields, in addition to the validator built-ins.
+const (
+	// TagPersonName accepts words of letters of any script joined by
+	// single hyphens, apostrophes or spaces, e.g. "Anna-Maria" or "O'Neil".
+	TagPersonName = "personname"
+	// TagGender accepts the genders of models.Genders.
+	TagGender = "gender"
+
+	// TagName, TagAge and TagNationality hold every rule of a person field,
+	// so that the structs people are written from cannot disagree on them.
+	TagName        = "person_name"
+	TagAge         = "person_age"
+	TagNationality = "person_nationality"
+)
+
+// aliases are the rules the person field tags stand for.
+var aliases = map[string]string{
+	TagName:        fmt.Sprintf("max=%d,%s", models.MaxNameLength, TagPersonName),
+	TagAge:         fmt.Sprintf("min=0,max=%d", models.MaxAge),
+	TagNationality: "iso3166_1_alpha2",
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON names, as clients send them.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	for alias, rules := range aliases {
+		v.RegisterAlias(alias, rules)
+	}
+
+	v.RegisterValidation(TagPersonName, func(fl validator.FieldLevel) bool {
+		return IsPersonName(fl.Field().String())
+	})
+	v.RegisterValidation(TagGender, func(fl validator.FieldLevel) bool {
+		for _, g := range models.Genders {
+			if fl.Field().String() == g {
+				return true
+			}
+		}
+		return false
+	})
+
+	return v
+}
+
+// Struct validates a struct by its validate tags. Failed rules are reported
+// as validator.ValidationErrors.
+func Struct(s interface{}) error {
+	return validate.Struct(s)
+}
+
+// Rules returns the rules a tag stands for: the rules of a person field tag,
+// or the tag itself.
+func Rules(tag string) []string {
+	if rules, ok := aliases[tag]; ok {
+		return strings.Split(rules, ",")
+	}
+	return []string{tag}
+}
+
+// IsPersonName reports whether the value is a well-formed name.
+func IsPersonName(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	prev := ' '
+	for _, r := range value {
+		switch {
+		case unicode.IsLetter(r) || unicode.Is(unicode.Mn, r):
+		case isSeparator(r):
+			if isSeparator(prev) {
+				return false
+			}
+		default:
+			return false
+		}
+		prev = r
+	}
+
+	return !isSeparator(prev)
+}
+
+func isSeparator(r rune) bool {
+	switch r {
+	case '-', '\'', '’', ' ':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsPersonName(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"Ivan", true},
+		{"Иван", true},
+		{"Anna-Maria", true},
+		{"O'Neil", true},
+		{"O’Neil", true},
+		{"Van der Berg", true},
+		{"émile", true},
+		{"", false},
+		{" Ivan", false},
+		{"Ivan ", false},
+		{"Anna--Maria", false},
+		{"Anna- Maria", false},
+		{"-Anna", false},
+		{"Ivan2", false},
+		{"Ivan!", false},
+		{"<b>", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPersonName(tt.value); got != tt.want {
+			t.Errorf("IsPersonName(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestStructPersonFields(t *testing.T) {
+	type person struct {
+		Name        string `json:"name" validate:"required,person_name"`
+		Age         int    `json:"age" validate:"person_age"`
+		Gender      string `json:"gender,omitempty" validate:"omitempty,gender"`
+		Nationality string `json:"nationality,omitempty" validate:"omitempty,person_nationality"`
+	}
+
+	tests := []struct {
+		name    string
+		person  person
+		wantErr bool
+	}{
+		{"valid", person{Name: "Ivan", Age: 30, Gender: "male", Nationality: "RU"}, false},
+		{"empty optional fields", person{Name: "Ivan"}, false},
+		{"long name", person{Name: strings.Repeat("a", 256)}, true},
+		{"malformed name", person{Name: "Ivan2"}, true},
+		{"negative age", person{Name: "Ivan", Age: -1}, true},
+		{"too old", person{Name: "Ivan", Age: 151}, true},
+		{"unknown gender", person{Name: "Ivan", Gender: "other"}, true},
+		{"unknown nationality", person{Name: "Ivan", Nationality: "XX"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Struct(tt.person); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
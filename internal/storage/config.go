@@ -1,9 +1,19 @@
-package storage
-
-type PostgresConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-}
+package storage
+
+import "time"
+
+type PostgresConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+
+	// ConnectTimeout bounds the check that the database is reachable when
+	// the storage is opened.
+	ConnectTimeout time.Duration
+
+	// IdentityFields are the person fields whose normalized values must be
+	// unique together. Empty disables the uniqueness check.
+	IdentityFields []string
+}
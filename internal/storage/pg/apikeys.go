@@ -0,0 +1,81 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	goqu "github.com/doug-martin/goqu/v9"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/storage"
+)
+
+var apiKeyColumns = []interface{}{"id", "name", "role", "created_at", "revoked_at"}
+
+// CreateAPIKey stores the hash of a new API key granting the role.
+func (s *Storage) CreateAPIKey(ctx context.Context, name, role, keyHash string) (models.APIKey, error) {
+	const op = "storage.pg.CreateAPIKey"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	var key models.APIKey
+	err := s.db.QueryRowContext(ctx, "INSERT INTO api_keys(name, role, key_hash) VALUES($1, $2, $3) RETURNING id, name, role, created_at",
+		name, role, keyHash).Scan(&key.Id, &key.Name, &key.Role, &key.CreatedAt)
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// FindAPIKey returns the unrevoked API key with the given hash.
+func (s *Storage) FindAPIKey(ctx context.Context, keyHash string) (models.APIKey, error) {
+	const op = "storage.pg.FindAPIKey"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	var key models.APIKey
+	found, err := s.goquDb.From("api_keys").Select(apiKeyColumns...).Where(
+		goqu.C("key_hash").Eq(keyHash),
+		goqu.C("revoked_at").IsNull(),
+	).ScanStructContext(ctx, &key)
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !found {
+		return models.APIKey{}, fmt.Errorf("%s: %w", op, storage.ErrAPIKeyNotFound)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys returns every issued API key, revoked ones included.
+func (s *Storage) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	const op = "storage.pg.ListAPIKeys"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	keys := make([]models.APIKey, 0)
+	if err := s.goquDb.From("api_keys").Select(apiKeyColumns...).Order(goqu.C("id").Asc()).ScanStructsContext(ctx, &keys); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey makes the API key unusable.
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int) error {
+	const op = "storage.pg.RevokeAPIKey"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	res, err := s.db.ExecContext(ctx, "UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAPIKeyNotFound)
+	}
+
+	return nil
+}
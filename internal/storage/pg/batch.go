@@ -0,0 +1,77 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/storage"
+)
+
+// SavePeople inserts people in a single transaction and reports the outcome
+// of each one in input order. In atomic mode nothing is stored unless every
+// person is, and the people that did insert fail with storage.ErrBatchAborted;
+// conflicts with them are reported as storage.BatchConflictError.
+// Otherwise a failed insert is rolled back alone and the rest are kept.
+func (s *Storage) SavePeople(ctx context.Context, people []models.Person, atomic bool) ([]storage.SaveResult, error) {
+	const op = "storage.pg.SavePeople"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	results := make([]storage.SaveResult, len(people))
+	inserted := make(map[int]int)
+	failed := false
+
+	for i, person := range people {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT person_insert"); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		key := s.identityKey(person)
+
+		var id int
+		err := tx.QueryRowContext(ctx, insertPersonQuery, s.insertPersonArgs(person)...).Scan(&id)
+		if err != nil {
+			failed = true
+			if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT person_insert"); rbErr != nil {
+				return nil, fmt.Errorf("%s: %w", op, rbErr)
+			}
+			results[i].Err = s.conflict(ctx, tx, err, key)
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT person_insert"); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		results[i].Id = id
+		inserted[id] = i
+	}
+
+	if atomic && failed {
+		for i := range results {
+			var existsErr *storage.ExistsError
+			switch {
+			case results[i].Err == nil:
+				results[i] = storage.SaveResult{Err: storage.ErrBatchAborted}
+			case errors.As(results[i].Err, &existsErr):
+				if j, ok := inserted[existsErr.Id]; ok {
+					results[i].Err = &storage.BatchConflictError{Index: j}
+				}
+			}
+		}
+		return results, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
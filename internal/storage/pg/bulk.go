@@ -0,0 +1,86 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	goqu "github.com/doug-martin/goqu/v9"
+
+	"people-service/internal/domain/models"
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/storage"
+)
+
+// UpdatePeople sets the non-empty attributes of changes on every person
+// matching the filters. The update is rolled back with
+// storage.ErrAffectedDiffer unless it affects exactly expected rows.
+func (s *Storage) UpdatePeople(ctx context.Context, params queryparam.Params, changes models.Person, expected int) (int, error) {
+	const op = "storage.pg.UpdatePeople"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	record := goqu.Record{"updated_at": goqu.L("now()")}
+	if changes.Age != 0 {
+		record["age"] = changes.Age
+		record["age_source"] = changes.AgeSource
+	}
+	if changes.Gender != "" {
+		record["gender"] = changes.Gender
+		record["gender_source"] = changes.GenderSource
+	}
+	if changes.Nationality != "" {
+		record["nationality"] = changes.Nationality
+		record["nationality_source"] = changes.NationalitySource
+	}
+
+	tx, err := s.goquDb.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Update("people").Set(record).Where(filterExpressions(params)...).Executor().ExecContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.commitAffected(op, tx, res.RowsAffected, expected)
+}
+
+// DeletePeople deletes every person matching the filters. The deletion is
+// rolled back with storage.ErrAffectedDiffer unless it affects exactly
+// expected rows.
+func (s *Storage) DeletePeople(ctx context.Context, params queryparam.Params, expected int) (int, error) {
+	const op = "storage.pg.DeletePeople"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	tx, err := s.goquDb.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Delete("people").Where(filterExpressions(params)...).Executor().ExecContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.commitAffected(op, tx, res.RowsAffected, expected)
+}
+
+func (s *Storage) commitAffected(op string, tx *goqu.TxDatabase, rowsAffected func() (int64, error), expected int) (int, error) {
+	affected, err := rowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if int(affected) != expected {
+		return int(affected), fmt.Errorf("%s: %d instead of %d: %w", op, affected, expected, storage.ErrAffectedDiffer)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(affected), nil
+}
@@ -0,0 +1,180 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	goqu "github.com/doug-martin/goqu/v9"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/translit"
+	"people-service/internal/storage"
+)
+
+// searchKey is the transliteration-aware form of the full name that trigram
+// comparisons run against.
+func searchKey(person models.Person) string {
+	return translit.Fold(strings.Join([]string{person.Name, person.Surname, person.Patronymic}, " "))
+}
+
+// BackfillSearchKeys fills search keys of people stored before they were
+// introduced.
+func (s *Storage) BackfillSearchKeys(ctx context.Context) error {
+	const op = "storage.pg.BackfillSearchKeys"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, surname, coalesce(patronymic, '') FROM people WHERE search_key = ''")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var people []models.Person
+	for rows.Next() {
+		var p models.Person
+		if err := rows.Scan(&p.Id, &p.Name, &p.Surname, &p.Patronymic); err != nil {
+			rows.Close()
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		people = append(people, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, p := range people {
+		if _, err := s.db.ExecContext(ctx, "UPDATE people SET search_key = $2 WHERE id = $1", p.Id, searchKey(p)); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// FindDuplicates returns people whose folded names are similar to the ones of
+// the person with the given id, most similar first.
+func (s *Storage) FindDuplicates(ctx context.Context, id int, threshold float64, limit int) ([]models.Duplicate, error) {
+	const op = "storage.pg.FindDuplicates"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	person, err := s.GetPersonById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	key := searchKey(person)
+
+	tx, err := s.goquDb.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// The % operator can use the trigram index but only takes its threshold
+	// from the session, so set it for this transaction.
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('pg_trgm.similarity_threshold', $1, true)",
+		strconv.FormatFloat(threshold, 'f', -1, 64)); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	score := goqu.L("similarity(search_key, ?)", key)
+
+	var rows []struct {
+		models.Person
+		Score float64 `db:"score"`
+	}
+	err = tx.From("people").
+		Select(append(personColumns, score.As("score"))...).
+		Where(
+			goqu.C("id").Neq(id),
+			goqu.L("search_key % ?", key),
+		).
+		Order(goqu.I("score").Desc(), goqu.C("id").Asc()).
+		Limit(uint(limit)).
+		ScanStructs(&rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	duplicates := make([]models.Duplicate, 0, len(rows))
+	for _, r := range rows {
+		duplicates = append(duplicates, models.Duplicate{Person: r.Person, Score: r.Score})
+	}
+
+	return duplicates, nil
+}
+
+// MergePeople stores merged as the target person, removes the source person
+// and keeps the source id, and the ids merged into the source before, as
+// aliases of the target.
+func (s *Storage) MergePeople(ctx context.Context, targetId, sourceId int, merged models.Person) error {
+	const op = "storage.pg.MergePeople"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// Aliases of the source go with it when it is deleted, so they are moved
+	// to the target first.
+	if _, err := tx.ExecContext(ctx, "UPDATE person_aliases SET person_id = $1 WHERE person_id = $2", targetId, sourceId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM people WHERE id = $1", sourceId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s: source %d: %w", op, sourceId, storage.ErrPersonNotFound)
+	}
+
+	key := s.identityKey(merged)
+	res, err = tx.ExecContext(ctx, updatePersonQuery, s.updatePersonArgs(targetId, merged)...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, s.conflict(ctx, s.db, err, key))
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s: target %d: %w", op, targetId, storage.ErrPersonNotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE people SET enriched_at = $2 WHERE id = $1", targetId, merged.EnrichedAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO person_aliases(alias_id, person_id) VALUES($1, $2)", sourceId, targetId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ResolveAlias returns the id of the person the merged id now belongs to.
+func (s *Storage) ResolveAlias(ctx context.Context, id int) (int, error) {
+	const op = "storage.pg.ResolveAlias"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	var personId int
+	err := s.db.QueryRowContext(ctx, "SELECT person_id FROM person_aliases WHERE alias_id = $1", id).Scan(&personId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return personId, nil
+}
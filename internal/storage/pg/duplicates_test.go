@@ -0,0 +1,91 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/lib/pq"
+
+	"people-service/internal/domain/models"
+)
+
+// newTestStorage opens and migrates the database named by PS_TEST_PG_URL, a
+// postgres:// URL. Tests needing a database are skipped without it.
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	url := os.Getenv("PS_TEST_PG_URL")
+	if url == "" {
+		t.Skip("PS_TEST_PG_URL is not set")
+	}
+
+	m, err := migrate.New("file://../../../migrations", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &Storage{
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		db:     db,
+		goquDb: goqu.New("postgres", db),
+	}
+}
+
+func TestMergePeopleKeepsAliases(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	var ids []int
+	for _, name := range []string{"Anna", "Boris", "Vera"} {
+		id, err := s.SavePerson(ctx, models.Person{Name: name, Surname: "Mergetest"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	t.Cleanup(func() {
+		s.db.Exec("DELETE FROM people WHERE id = ANY($1)", pq.Array(ids))
+	})
+	a, b, c := ids[0], ids[1], ids[2]
+
+	merge := func(targetId, sourceId int) {
+		t.Helper()
+		target, err := s.GetPersonById(ctx, targetId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.MergePeople(ctx, targetId, sourceId, target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	merge(b, a)
+	merge(c, b)
+
+	for _, id := range []int{a, b} {
+		got, err := s.ResolveAlias(ctx, id)
+		if err != nil {
+			t.Fatalf("ResolveAlias(%d): %v", id, err)
+		}
+		if got != c {
+			t.Errorf("ResolveAlias(%d) = %d, want %d", id, got, c)
+		}
+	}
+}
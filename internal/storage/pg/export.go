@@ -0,0 +1,72 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"people-service/internal/domain/models"
+	queryparam "people-service/internal/lib/query-param"
+)
+
+const exportFetchSize = 500
+
+// ExportPeople calls fn for every person matching the filters in sort order.
+// Rows are read through a server-side cursor, exportFetchSize at a time, so
+// the result set is never held in memory. Paging parameters are ignored.
+// An error returned by fn stops the export and is returned as is.
+func (s *Storage) ExportPeople(ctx context.Context, params queryparam.Params, fn func(models.Person) error) error {
+	const op = "storage.pg.ExportPeople"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	query, args, err := s.goquDb.Select(
+		personColumns...,
+	).From(
+		"people",
+	).Where(
+		filterExpressions(params)...,
+	).Order(
+		orderExpressions(params.Sort, false)...,
+	).ToSQL()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := s.goquDb.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DECLARE people_export NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for {
+		var people []models.Person
+		if err := tx.ScanStructsContext(ctx, &people, fmt.Sprintf("FETCH FORWARD %d FROM people_export", exportFetchSize)); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, p := range people {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+
+		if len(people) < exportFetchSize {
+			break
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "CLOSE people_export"); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package pg
+
+import (
+	"strings"
+
+	goqu "github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/lib/translit"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// nullDefaults holds the person columns that rows written before enrichment
+// existed may leave NULL, with the value they read as.
+var nullDefaults = map[string]interface{}{
+	"patronymic":  "",
+	"age":         0,
+	"gender":      "",
+	"nationality": "",
+}
+
+type columnExpression interface {
+	exp.Expression
+	exp.Comparable
+	exp.Inable
+	exp.Likeable
+	exp.Orderable
+}
+
+// column returns the expression reading a person column, so that filters,
+// sorting and cursors treat NULL the same way scanning does.
+func column(name string) columnExpression {
+	if def, ok := nullDefaults[name]; ok {
+		return goqu.COALESCE(goqu.C(name), def)
+	}
+	return goqu.C(name)
+}
+
+// selectColumn returns the select list entry of a person column.
+func selectColumn(name string) interface{} {
+	if def, ok := nullDefaults[name]; ok {
+		return goqu.COALESCE(goqu.C(name), def).As(name)
+	}
+	return name
+}
+
+// filterExpressions translates person filters into WHERE conditions.
+func filterExpressions(params queryparam.Params) []exp.Expression {
+	var exprs []exp.Expression
+
+	if len(params.Ids) > 0 {
+		exprs = append(exprs, goqu.C("id").In(params.Ids))
+	}
+
+	nameFilters := []struct {
+		column string
+		latin  string
+		filter *queryparam.StringFilter
+	}{
+		{"name", "name_latin", params.Name},
+		{"surname", "surname_latin", params.Surname},
+		{"patronymic", "patronymic_latin", params.Patronymic},
+	}
+	for _, f := range nameFilters {
+		if f.filter != nil {
+			exprs = append(exprs, nameExpression(f.column, f.latin, f.filter))
+		}
+	}
+
+	strFilters := []struct {
+		column string
+		filter *queryparam.StringFilter
+	}{
+		{"gender", params.Gender},
+		{"nationality", params.Nationality},
+	}
+	for _, f := range strFilters {
+		if f.filter != nil {
+			exprs = append(exprs, stringExpression(column(f.column), f.filter))
+		}
+	}
+
+	if params.Age != nil {
+		exprs = append(exprs, column("age").Eq(*params.Age))
+	}
+	if params.AgeMin != nil {
+		exprs = append(exprs, column("age").Gte(*params.AgeMin))
+	}
+	if params.AgeMax != nil {
+		exprs = append(exprs, column("age").Lte(*params.AgeMax))
+	}
+
+	if params.CreatedAfter != nil {
+		exprs = append(exprs, goqu.C("created_at").Gte(*params.CreatedAfter))
+	}
+	if params.CreatedBefore != nil {
+		exprs = append(exprs, goqu.C("created_at").Lt(*params.CreatedBefore))
+	}
+	if params.UpdatedAfter != nil {
+		exprs = append(exprs, goqu.C("updated_at").Gte(*params.UpdatedAfter))
+	}
+	if params.UpdatedBefore != nil {
+		exprs = append(exprs, goqu.C("updated_at").Lt(*params.UpdatedBefore))
+	}
+
+	return exprs
+}
+
+// orderExpressions translates the requested sort into ORDER BY terms.
+// Reversed order is used to read the page before a cursor.
+func orderExpressions(sort []queryparam.SortField, reverse bool) []exp.OrderedExpression {
+	order := make([]exp.OrderedExpression, 0, len(sort))
+	for _, f := range sort {
+		if f.Desc != reverse {
+			order = append(order, column(f.Column).Desc())
+		} else {
+			order = append(order, column(f.Column).Asc())
+		}
+	}
+	return order
+}
+
+// keysetExpression selects rows that follow the cursor in the sort order,
+// or precede it when reverse is set:
+// (c1 > v1) OR (c1 = v1 AND c2 > v2) OR ...
+func keysetExpression(sort []queryparam.SortField, cursor *queryparam.Cursor, reverse bool) exp.Expression {
+	var alternatives []exp.Expression
+
+	for i, f := range sort {
+		conds := make([]exp.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, column(sort[j].Column).Eq(cursor.Values[j]))
+		}
+
+		col := column(f.Column)
+		if f.Desc != reverse {
+			conds = append(conds, col.Lt(cursor.Values[i]))
+		} else {
+			conds = append(conds, col.Gt(cursor.Values[i]))
+		}
+
+		alternatives = append(alternatives, goqu.And(conds...))
+	}
+
+	return goqu.Or(alternatives...)
+}
+
+func stringExpression(col columnExpression, f *queryparam.StringFilter) exp.Expression {
+	switch f.Op {
+	case queryparam.OpIn:
+		if f.Negate {
+			return col.NotIn(f.Values)
+		}
+		return col.In(f.Values)
+	case queryparam.OpPrefix:
+		return like(col, likeEscaper.Replace(f.Values[0])+"%", f.Negate)
+	case queryparam.OpContains:
+		return like(col, "%"+likeEscaper.Replace(f.Values[0])+"%", f.Negate)
+	case queryparam.OpLike:
+		return like(col, f.Values[0], f.Negate)
+	default:
+		if f.Negate {
+			return col.Neq(f.Values[0])
+		}
+		return col.Eq(f.Values[0])
+	}
+}
+
+// nameExpression matches a name in either script: the operand is compared
+// with the name as stored and, transliterated, with its Latin form.
+func nameExpression(name, latin string, f *queryparam.StringFilter) exp.Expression {
+	latinFilter := *f
+	latinFilter.Values = make([]string, len(f.Values))
+	for i, v := range f.Values {
+		latinFilter.Values[i] = translit.Latin(v)
+	}
+
+	original := stringExpression(column(name), f)
+	transliterated := stringExpression(goqu.C(latin), &latinFilter)
+
+	// A negated filter excludes matches in both scripts.
+	if f.Negate {
+		return goqu.And(original, transliterated)
+	}
+	return goqu.Or(original, transliterated)
+}
+
+func like(col columnExpression, pattern string, negate bool) exp.Expression {
+	if negate {
+		return col.NotILike(pattern)
+	}
+	return col.ILike(pattern)
+}
@@ -0,0 +1,54 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"people-service/internal/storage"
+)
+
+// SchemaVersion is the migration the service expects the database to be at.
+// Bump it along with every new migration.
+const SchemaVersion = 11
+
+// Ping checks the database is reachable.
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.pg.Ping"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// CheckSchema checks the migrations recorded by the migrator are at least
+// SchemaVersion and none failed halfway, giving storage.ErrSchemaOutdated
+// otherwise.
+func (s *Storage) CheckSchema(ctx context.Context) error {
+	const op = "storage.pg.CheckSchema"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	var (
+		version int
+		dirty   bool
+	)
+	err := s.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: no migrations applied: %w", op, storage.ErrSchemaOutdated)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if dirty {
+		return fmt.Errorf("%s: migration %d is dirty: %w", op, version, storage.ErrSchemaOutdated)
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("%s: at version %d, want %d: %w", op, version, SchemaVersion, storage.ErrSchemaOutdated)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/storage"
+)
+
+// ReserveIdempotencyKey claims the key for a request with the given hash
+// until the lease runs out, after which a request holding a key that was
+// neither saved nor released is taken for dead and the key is free again. It returns nil if the request is to be processed, or the
+// stored response of an earlier request with the same key and hash.
+// Keys used with another hash give storage.ErrIdempotencyKeyMismatch, keys of
+// requests still in progress storage.ErrIdempotencyKeyInUse.
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, key, requestHash string, lease time.Duration) (*models.IdempotentResponse, error) {
+	const op = "storage.pg.ReserveIdempotencyKey"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at < now()"); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO idempotency_keys(key, request_hash, expires_at)
+		VALUES($1, $2, now() + $3 * interval '1 second')
+		ON CONFLICT (key) DO NOTHING`, key, requestHash, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+
+	var (
+		storedHash string
+		status     sql.NullInt64
+		headers    []byte
+		body       []byte
+	)
+	err = s.db.QueryRowContext(ctx, "SELECT request_hash, status, headers, body FROM idempotency_keys WHERE key = $1", key).
+		Scan(&storedHash, &status, &headers, &body)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released by the request holding it in the meantime.
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyInUse)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if storedHash != requestHash {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyMismatch)
+	}
+	if !status.Valid {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyInUse)
+	}
+
+	response := &models.IdempotentResponse{Status: int(status.Int64), Body: body}
+	if err := json.Unmarshal(headers, &response.Header); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return response, nil
+}
+
+// SaveIdempotentResponse stores the response to the request holding the key
+// and keeps it until ttl passes.
+func (s *Storage) SaveIdempotentResponse(ctx context.Context, key string, response models.IdempotentResponse, ttl time.Duration) error {
+	const op = "storage.pg.SaveIdempotentResponse"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `UPDATE idempotency_keys
+		SET status = $2, headers = $3, body = $4, expires_at = now() + $5 * interval '1 second'
+		WHERE key = $1`,
+		key, response.Status, headers, response.Body, ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey frees a key whose request failed, so that it can be
+// retried.
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	const op = "storage.pg.ReleaseIdempotencyKey"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE key = $1 AND status IS NULL", key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/storage"
+)
+
+const identitySettingKey = "identity_fields"
+
+// identityKey builds the normalized uniqueness key of the person from the
+// configured identity fields. It returns nil when uniqueness is disabled.
+func (s *Storage) identityKey(person models.Person) *string {
+	if len(s.identityFields) == 0 {
+		return nil
+	}
+
+	parts := make([]string, 0, len(s.identityFields))
+	for _, f := range s.identityFields {
+		var value string
+		switch f {
+		case "name":
+			value = person.Name
+		case "surname":
+			value = person.Surname
+		case "patronymic":
+			value = person.Patronymic
+		}
+		parts = append(parts, normalizeIdentity(value))
+	}
+
+	key := strings.Join(parts, "|")
+	return &key
+}
+
+func normalizeIdentity(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conflict converts a unique violation on the identity key into
+// storage.ExistsError pointing at the conflicting record, looked up with q.
+func (s *Storage) conflict(ctx context.Context, q rowQuerier, err error, key *string) error {
+	var pgxError *pq.Error
+	if !errors.As(err, &pgxError) || pgxError.Code != pgUniqueViolationCode || key == nil {
+		return err
+	}
+
+	var id int
+	if err := q.QueryRowContext(ctx, "SELECT id FROM people WHERE identity_key = $1", *key).Scan(&id); err != nil {
+		return storage.ErrPersonExists
+	}
+
+	return &storage.ExistsError{Id: id}
+}
+
+// SyncIdentityKeys recomputes identity keys of all people when the configured
+// identity fields differ from the ones the keys were built with.
+func (s *Storage) SyncIdentityKeys(ctx context.Context) error {
+	const op = "storage.pg.SyncIdentityKeys"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	configured := strings.Join(s.identityFields, ",")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRowContext(ctx, "SELECT value FROM people_settings WHERE key = $1 FOR UPDATE", identitySettingKey).Scan(&current)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err == nil && current == configured {
+		return nil
+	}
+
+	s.log.Info("rebuilding person identity keys",
+		slog.String("from", current),
+		slog.String("to", configured),
+	)
+
+	if _, err := tx.ExecContext(ctx, "UPDATE people SET identity_key = NULL"); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(s.identityFields) > 0 {
+		rows, err := tx.QueryContext(ctx, "SELECT id, name, surname, coalesce(patronymic, '') FROM people")
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		var people []models.Person
+		for rows.Next() {
+			var p models.Person
+			if err := rows.Scan(&p.Id, &p.Name, &p.Surname, &p.Patronymic); err != nil {
+				rows.Close()
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			people = append(people, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, p := range people {
+			key := s.identityKey(p)
+			if _, err := tx.ExecContext(ctx, "UPDATE people SET identity_key = $2 WHERE id = $1", p.Id, key); err != nil {
+				return fmt.Errorf("%s: cannot set identity key of person %d: %w", op, p.Id, err)
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO people_settings(key, value) VALUES($1, $2)
+						ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value`, identitySettingKey, configured)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
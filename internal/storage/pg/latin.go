@@ -0,0 +1,40 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	goqu "github.com/doug-martin/goqu/v9"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/translit"
+)
+
+// BackfillLatinNames fills Latin forms of names of people stored before they
+// were introduced.
+func (s *Storage) BackfillLatinNames(ctx context.Context) error {
+	const op = "storage.pg.BackfillLatinNames"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	var people []models.Person
+	err := s.goquDb.From("people").Select(
+		"id", "name", "surname", selectColumn("patronymic"),
+	).Where(
+		goqu.C("name_latin").Eq(""),
+		goqu.C("name").Neq(""),
+	).ScanStructsContext(ctx, &people)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, p := range people {
+		_, err := s.db.ExecContext(ctx, "UPDATE people SET name_latin = $2, surname_latin = $3, patronymic_latin = $4 WHERE id = $1",
+			p.Id, translit.Latin(p.Name), translit.Latin(p.Surname), translit.Latin(p.Patronymic))
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
@@ -1,192 +1,294 @@
-package pg
-
-import (
-	"database/sql"
-	"errors"
-	"fmt"
-	"strconv"
-
-	"log/slog"
-
-	goqu "github.com/doug-martin/goqu/v9"
-	"github.com/lib/pq"
-
-	"people-service/internal/domain/models"
-	queryparam "people-service/internal/lib/query-param"
-	"people-service/internal/storage"
-)
-
-const (
-	pgUniqueViolationCode = "23505"
-)
-
-type Storage struct {
-	log    *slog.Logger
-	db     *sql.DB
-	goquDb *goqu.Database
-}
-
-func New(log *slog.Logger, cfg storage.PostgresConfig) (*Storage, error) {
-	const op = "storage.pg.New"
-
-	connStr := fmt.Sprintf("host=%s port=%v user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host,
-		cfg.Port,
-		cfg.User,
-		cfg.Password,
-		cfg.DBName)
-
-	db, err := sql.Open("postgres", connStr)
-
-	if err != nil {
-		//log.Println("Cannot open DB connection: ", err)
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-
-	return &Storage{log: log, db: db, goquDb: goqu.New("postgres", db)}, nil
-}
-
-func (s *Storage) Close() {
-	s.db.Close()
-}
-
-func (s *Storage) SavePerson(person models.Person) (int, error) {
-	const op = "storage.pg.SavePerson"
-
-	var id int
-	err := s.db.QueryRow("INSERT INTO people(name, surname, patronymic, age, gender, nationality) VALUES($1, $2, $3, $4, $5, $6) RETURNING id",
-		person.Name,
-		person.Surname,
-		person.Patronymic,
-		person.Age,
-		person.Gender,
-		person.Nationality,
-	).Scan(&id)
-
-	var pgxError *pq.Error
-	if err != nil {
-		if errors.As(err, &pgxError) {
-			if pgxError.Code == pgUniqueViolationCode {
-				return 0, fmt.Errorf("%s: %w", op, storage.ErrPersonExists)
-			}
-			return 0, fmt.Errorf("%s: %w", op, err)
-		} else {
-			return 0, fmt.Errorf("%s: %w", op, err)
-		}
-	}
-
-	return id, nil
-}
-
-func (s *Storage) DeletePerson(id int) error {
-	const op = "storage.pg.DeletePerson"
-
-	stmt, err := s.db.Prepare("DELETE FROM people WHERE id = $1")
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.Exec(id)
-
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	return nil
-}
-
-func (s *Storage) GetPerson(params queryparam.Params) ([]models.Person, error) {
-
-	log := s.log
-
-	dq := s.goquDb.Select(
-		"id", "name", "surname", "patronymic", "age", "gender", "nationality",
-	).From(
-		"people",
-	)
-
-	if params.Id != "" {
-		dq = dq.Where(goqu.C("id").Eq(params.Id))
-	}
-	if params.Name != "" {
-		dq = dq.Where(goqu.C("name").Eq(params.Name))
-	}
-	if params.Surname != "" {
-		dq = dq.Where(goqu.C("surname").Eq(params.Surname))
-	}
-	if params.Patronymic != "" {
-		dq = dq.Where(goqu.C("patronymic").Eq(params.Patronymic))
-	}
-	if params.Age != "" {
-		dq = dq.Where(goqu.C("age").Eq(params.Age))
-	}
-	if params.Gender != "" {
-		dq = dq.Where(goqu.C("gender").Eq(params.Gender))
-	}
-	if params.Nationality != "" {
-		dq = dq.Where(goqu.C("nationality").Eq(params.Nationality))
-	}
-
-	if params.Offset != "" {
-		var offsetValue int
-		offsetValue, err := strconv.Atoi(params.Offset)
-		if err != nil {
-			log.Error(fmt.Sprintf("cannot parse offset value: %s", params.Offset))
-		} else {
-			ui := uint(offsetValue)
-			dq = dq.Offset(ui)
-		}
-	}
-
-	if params.Limit != "" {
-		var limitValue int
-		limitValue, err := strconv.Atoi(params.Limit)
-		if err != nil {
-			log.Error(fmt.Sprintf("cannot parse Limit value: %s", params.Limit))
-		} else {
-			ui := uint(limitValue)
-			dq = dq.Limit(ui)
-		}
-	}
-
-	sql, _, _ := dq.ToSQL()
-
-	fmt.Println(sql)
-
-	persons := make([]models.Person, 0)
-
-	if err := dq.ScanStructs(&persons); err != nil {
-		log.Error(fmt.Sprintf("cannot read persons from DB: %s", params.Limit))
-		return nil, err
-	}
-
-	return persons, nil
-}
-
-func (s *Storage) UpdatePerson(id int, person models.Person) error {
-	const op = "storage.pg.UpdatePerson"
-
-	stmt, err := s.db.Prepare(`UPDATE people
-								SET name=$2, surname=$3, patronymic=$4, age=$5, gender=$6, nationality=$7
-	 							WHERE id = $1`)
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.Exec(id,
-		person.Name,
-		person.Surname,
-		person.Patronymic,
-		person.Age,
-		person.Gender,
-		person.Nationality,
-	)
-
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	return nil
-}
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"log/slog"
+
+	"github.com/XSAM/otelsql"
+	goqu "github.com/doug-martin/goqu/v9"
+
+	"people-service/internal/domain/models"
+	queryparam "people-service/internal/lib/query-param"
+	"people-service/internal/lib/translit"
+	"people-service/internal/storage"
+)
+
+const (
+	pgUniqueViolationCode = "23505"
+)
+
+// personColumns are selected whenever full person records are read.
+var personColumns = []interface{}{
+	"id", "name", "surname",
+	selectColumn("patronymic"), selectColumn("age"), selectColumn("gender"), selectColumn("nationality"),
+	"name_latin", "surname_latin", "patronymic_latin",
+	"age_source", "gender_source", "nationality_source", "enriched_at",
+	"created_at", "updated_at",
+}
+
+const insertPersonQuery = `INSERT INTO people(name, surname, patronymic, age, gender, nationality,
+		age_source, gender_source, nationality_source, enriched_at, identity_key, search_key,
+		name_latin, surname_latin, patronymic_latin, created_at, updated_at)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, now(), now()) RETURNING id`
+
+const updatePersonQuery = `UPDATE people
+	SET name=$2, surname=$3, patronymic=$4, age=$5, gender=$6, nationality=$7,
+		age_source=$8, gender_source=$9, nationality_source=$10, identity_key=$11, search_key=$12,
+		name_latin=$13, surname_latin=$14, patronymic_latin=$15, updated_at=now()
+	WHERE id = $1`
+
+type Storage struct {
+	log            *slog.Logger
+	db             *sql.DB
+	goquDb         *goqu.Database
+	identityFields []string
+}
+
+func New(log *slog.Logger, cfg storage.PostgresConfig) (*Storage, error) {
+	const op = "storage.pg.New"
+
+	connStr := fmt.Sprintf("host=%s port=%v user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password,
+		cfg.DBName)
+
+	db, err := otelsql.Open("postgres", connStr, tracingOptions()...)
+
+	if err != nil {
+		//log.Println("Cannot open DB connection: ", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// sql.Open does not connect, so check the database is reachable to fail
+	// at startup rather than on the first request.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: database unreachable: %w", op, err)
+	}
+
+	return &Storage{
+		log:            log,
+		db:             db,
+		goquDb:         goqu.New("postgres", db),
+		identityFields: cfg.IdentityFields,
+	}, nil
+}
+
+func (s *Storage) Close() {
+	s.db.Close()
+}
+
+func (s *Storage) SavePerson(ctx context.Context, person models.Person) (int, error) {
+	const op = "storage.pg.SavePerson"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	key := s.identityKey(person)
+
+	var id int
+	err := s.db.QueryRowContext(ctx, insertPersonQuery, s.insertPersonArgs(person)...).Scan(&id)
+
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, s.conflict(ctx, s.db, err, key))
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetPersonById(ctx context.Context, id int) (models.Person, error) {
+	const op = "storage.pg.GetPersonById"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	var person models.Person
+	found, err := s.goquDb.From("people").Select(personColumns...).Where(goqu.C("id").Eq(id)).ScanStructContext(ctx, &person)
+	if err != nil {
+		return models.Person{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !found {
+		return models.Person{}, fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
+	}
+
+	return person, nil
+}
+
+func (s *Storage) DeletePerson(ctx context.Context, id int) error {
+	const op = "storage.pg.DeletePerson"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	stmt, err := s.db.PrepareContext(ctx, "DELETE FROM people WHERE id = $1")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, id)
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetPerson returns people matching the filters in the requested order.
+// When a Before cursor is given, the rows preceding it are returned, still in
+// the requested order.
+func (s *Storage) GetPerson(ctx context.Context, params queryparam.Params) ([]models.Person, error) {
+	const op = "storage.pg.GetPerson"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	reverse := params.Before != nil
+
+	dq := s.goquDb.Select(
+		selectedColumns(params)...,
+	).From(
+		"people",
+	).Where(
+		filterExpressions(params)...,
+	).Order(
+		orderExpressions(params.Sort, reverse)...,
+	)
+
+	if params.After != nil {
+		dq = dq.Where(keysetExpression(params.Sort, params.After, false))
+	}
+	if params.Before != nil {
+		dq = dq.Where(keysetExpression(params.Sort, params.Before, true))
+	}
+
+	if params.Offset > 0 {
+		dq = dq.Offset(params.Offset)
+	}
+	if params.Limit > 0 {
+		dq = dq.Limit(params.Limit)
+	}
+
+	persons := make([]models.Person, 0)
+
+	if err := dq.ScanStructsContext(ctx, &persons); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if reverse {
+		for i, j := 0, len(persons)-1; i < j; i, j = i+1, j-1 {
+			persons[i], persons[j] = persons[j], persons[i]
+		}
+	}
+
+	return persons, nil
+}
+
+// CountPerson returns the number of people matching the filters, ignoring
+// cursors and paging.
+func (s *Storage) CountPerson(ctx context.Context, params queryparam.Params) (int, error) {
+	const op = "storage.pg.CountPerson"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	total, err := s.goquDb.From("people").Where(filterExpressions(params)...).CountContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(total), nil
+}
+
+func (s *Storage) UpdatePerson(ctx context.Context, id int, person models.Person) error {
+	const op = "storage.pg.UpdatePerson"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	stmt, err := s.db.PrepareContext(ctx, updatePersonQuery)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	key := s.identityKey(person)
+
+	_, err = stmt.ExecContext(ctx, s.updatePersonArgs(id, person)...)
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, s.conflict(ctx, s.db, err, key))
+	}
+
+	return nil
+}
+
+func (s *Storage) insertPersonArgs(person models.Person) []interface{} {
+	return []interface{}{
+		person.Name,
+		person.Surname,
+		person.Patronymic,
+		person.Age,
+		person.Gender,
+		person.Nationality,
+		person.AgeSource,
+		person.GenderSource,
+		person.NationalitySource,
+		person.EnrichedAt,
+		s.identityKey(person),
+		searchKey(person),
+		translit.Latin(person.Name),
+		translit.Latin(person.Surname),
+		translit.Latin(person.Patronymic),
+	}
+}
+
+func (s *Storage) updatePersonArgs(id int, person models.Person) []interface{} {
+	return []interface{}{
+		id,
+		person.Name,
+		person.Surname,
+		person.Patronymic,
+		person.Age,
+		person.Gender,
+		person.Nationality,
+		person.AgeSource,
+		person.GenderSource,
+		person.NationalitySource,
+		s.identityKey(person),
+		searchKey(person),
+		translit.Latin(person.Name),
+		translit.Latin(person.Surname),
+		translit.Latin(person.Patronymic),
+	}
+}
+
+// selectedColumns returns the requested fields along with id and the sort
+// columns, which cursors are built from, or personColumns if no fields are
+// requested.
+func selectedColumns(params queryparam.Params) []interface{} {
+	if len(params.Fields) == 0 {
+		return personColumns
+	}
+
+	seen := make(map[string]bool)
+	var columns []interface{}
+	add := func(column string) {
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, selectColumn(column))
+		}
+	}
+
+	add("id")
+	for _, f := range params.Sort {
+		add(f.Column)
+	}
+	for _, f := range params.Fields {
+		add(f)
+	}
+
+	return columns
+}
@@ -0,0 +1,90 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	goqu "github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+
+	"people-service/internal/domain/models"
+	"people-service/internal/lib/translit"
+)
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+
+// SearchPeople finds people by words of their names. Every query word must
+// match a name by prefix, in the original script or transliterated; names
+// similar to the query are returned as well to tolerate misspellings.
+// Results are ranked by relevance.
+func (s *Storage) SearchPeople(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	const op = "storage.pg.SearchPeople"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	tsQuery := buildTsQuery(query)
+	folded := translit.Fold(query)
+	if tsQuery == "" && folded == "" {
+		return []models.SearchResult{}, nil
+	}
+
+	tsq := goqu.L("to_tsquery('simple', ?)", tsQuery)
+	fullName := goqu.L("name || ' ' || surname || ' ' || coalesce(patronymic, '')")
+
+	rank := goqu.L("ts_rank(search_tsv, ?) + word_similarity(?, search_key)", tsq, folded)
+	highlight := goqu.L("ts_headline('simple', ?, ?, ?)", escapeHTML(fullName), tsq, headlineOptions)
+
+	var rows []struct {
+		models.Person
+		Rank      float64 `db:"rank"`
+		Highlight string  `db:"highlight"`
+	}
+	err := s.goquDb.From("people").
+		Select(append(personColumns, rank.As("rank"), highlight.As("highlight"))...).
+		Where(goqu.Or(
+			goqu.L("search_tsv @@ ?", tsq),
+			goqu.L("? <% search_key", folded),
+		)).
+		Order(goqu.I("rank").Desc(), goqu.C("id").Asc()).
+		Limit(uint(limit)).
+		ScanStructs(&rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	results := make([]models.SearchResult, 0, len(rows))
+	for _, r := range rows {
+		results = append(results, models.SearchResult{Person: r.Person, Rank: r.Rank, Highlight: r.Highlight})
+	}
+
+	return results, nil
+}
+
+// escapeHTML is html.EscapeString in SQL. ts_headline marks words up but leaves
+// the rest of the text as it is, so names are escaped before it runs to keep
+// its <mark> tags the only markup of a highlight.
+func escapeHTML(text interface{}) exp.LiteralExpression {
+	return goqu.L(`replace(replace(replace(replace(replace(?, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`, text)
+}
+
+// buildTsQuery turns free text into a prefix tsquery where each word matches
+// either as written or in its folded Latin form: "Дмитр Иван" becomes
+// "(дмитр:* | dmitr:*) & (иван:* | ivan:*)".
+func buildTsQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		variants := []string{w + ":*"}
+		if f := translit.Fold(w); f != "" && f != w {
+			variants = append(variants, f+":*")
+		}
+		terms = append(terms, "("+strings.Join(variants, " | ")+")")
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,56 @@
+package pg
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"people-service/internal/domain/models"
+)
+
+func TestBuildTsQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"  ,. ", ""},
+		{"ivan", "(ivan:*)"},
+		{"Дмитр Иван", "(дмитр:* | dmitr:*) & (иван:* | ivan:*)"},
+		{"o'brien:* & !ivan", "(o:*) & (brien:*) & (ivan:*)"},
+	}
+
+	for _, tt := range tests {
+		if got := buildTsQuery(tt.query); got != tt.want {
+			t.Errorf("buildTsQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchPeopleEscapesHighlights(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.SavePerson(ctx, models.Person{Name: "Searchtest", Surname: "<b>&'\""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		s.db.Exec("DELETE FROM people WHERE id = $1", id)
+	})
+
+	results, err := s.SearchPeople(ctx, "searchtest", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Person.Id != id {
+			continue
+		}
+		if !strings.Contains(r.Highlight, "&lt;b&gt;&amp;&#39;&#34;") || strings.Contains(r.Highlight, "<b>") {
+			t.Errorf("got highlight %q, want the surname escaped", r.Highlight)
+		}
+		return
+	}
+	t.Fatalf("person %d not found", id)
+}
@@ -0,0 +1,89 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	goqu "github.com/doug-martin/goqu/v9"
+
+	"people-service/internal/domain/models"
+	queryparam "people-service/internal/lib/query-param"
+)
+
+// GetStats aggregates people matching the filters. Ages of 0 are unknown and
+// are left out of age statistics.
+func (s *Storage) GetStats(ctx context.Context, params queryparam.Params, bucketWidth int) (models.Stats, error) {
+	const op = "storage.pg.GetStats"
+	ctx, done := track(ctx, op)
+	defer done()
+
+	filtered := s.goquDb.From("people").Where(filterExpressions(params)...)
+
+	var totals struct {
+		Total       int `db:"total"`
+		Age         int `db:"age"`
+		Gender      int `db:"gender"`
+		Nationality int `db:"nationality"`
+		Enriched    int `db:"enriched"`
+	}
+	_, err := filtered.Select(
+		goqu.COUNT(goqu.Star()).As("total"),
+		goqu.L("count(*) FILTER (WHERE age > 0)").As("age"),
+		goqu.L("count(*) FILTER (WHERE gender <> '')").As("gender"),
+		goqu.L("count(*) FILTER (WHERE nationality <> '')").As("nationality"),
+		goqu.L("count(*) FILTER (WHERE enriched_at IS NOT NULL)").As("enriched"),
+	).ScanStructContext(ctx, &totals)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats := models.Stats{
+		Total:         totals.Total,
+		ByGender:      make([]models.GenderCount, 0),
+		ByNationality: make([]models.NationalityStats, 0),
+		ByAge:         make([]models.AgeBucket, 0),
+		Coverage: models.Coverage{
+			Age:         percent(totals.Age, totals.Total),
+			Gender:      percent(totals.Gender, totals.Total),
+			Nationality: percent(totals.Nationality, totals.Total),
+			Enriched:    percent(totals.Enriched, totals.Total),
+		},
+	}
+
+	err = filtered.Select(
+		selectColumn("gender"),
+		goqu.COUNT(goqu.Star()).As("count"),
+	).GroupBy(column("gender")).Order(goqu.I("count").Desc(), column("gender").Asc()).ScanStructsContext(ctx, &stats.ByGender)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = filtered.Select(
+		selectColumn("nationality"),
+		goqu.COUNT(goqu.Star()).As("count"),
+		goqu.L("avg(age) FILTER (WHERE age > 0)").As("avg_age"),
+		goqu.L("percentile_cont(0.5) WITHIN GROUP (ORDER BY age) FILTER (WHERE age > 0)").As("median_age"),
+	).GroupBy(column("nationality")).Order(goqu.I("count").Desc(), column("nationality").Asc()).ScanStructsContext(ctx, &stats.ByNationality)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	bucket := goqu.L("(age / ?) * ?", bucketWidth, bucketWidth)
+	err = filtered.Select(
+		bucket.As("from"),
+		goqu.L("(age / ?) * ? + ?", bucketWidth, bucketWidth, bucketWidth-1).As("to"),
+		goqu.COUNT(goqu.Star()).As("count"),
+	).Where(column("age").Gt(0)).GroupBy(goqu.I("from"), goqu.I("to")).Order(goqu.I("from").Asc()).ScanStructsContext(ctx, &stats.ByAge)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return stats, nil
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
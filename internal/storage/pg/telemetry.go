@@ -0,0 +1,82 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"people-service/internal/lib/metrics"
+)
+
+var tracer = otel.Tracer("people-service/internal/storage/pg")
+
+// track starts a span for the storage call op, if it is made within a
+// trace, and returns its context along with a func that ends the span and
+// records the latency of the call. Call it first thing in the call and defer
+// the func.
+func track(ctx context.Context, op string) (context.Context, func()) {
+	start := time.Now()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		ctx, span = tracer.Start(ctx, op)
+	}
+
+	return ctx, func() {
+		query := strings.TrimPrefix(op, "storage.pg.")
+		metrics.DBQueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+		span.End()
+	}
+}
+
+// Stats returns the connection pool statistics of the database.
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// tracingOptions make the driver trace the statements of storage calls,
+// with literals in the SQL replaced so that no personal data ends up in
+// traces.
+func tracingOptions() []otelsql.Option {
+	return []otelsql.Option{
+		otelsql.WithAttributes(attribute.String("db.system", "postgresql")),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableQuery:         true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			// Only statements made within a traced call, not the
+			// connection pool housekeeping.
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+		otelsql.WithAttributesGetter(func(_ context.Context, _ otelsql.Method, query string, _ []driver.NamedValue) []attribute.KeyValue {
+			if query == "" {
+				return nil
+			}
+			return []attribute.KeyValue{attribute.String("db.statement", sanitize(query))}
+		}),
+	}
+}
+
+var (
+	stringLiteral  = regexp.MustCompile(`(?:[Ee])?'(?:[^']|'')*'`)
+	numericLiteral = regexp.MustCompile(`(^|[^\w$.])\d+(?:\.\d+)?`)
+	whitespace     = regexp.MustCompile(`\s+`)
+)
+
+// sanitize replaces the string and numeric literals of query with ?, as
+// goqu inlines values into the SQL it builds. Placeholders such as $1 are
+// kept.
+func sanitize(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	query = numericLiteral.ReplaceAllString(query, "${1}?")
+	return strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+}
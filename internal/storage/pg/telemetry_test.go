@@ -0,0 +1,25 @@
+package pg
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`SELECT * FROM "people" WHERE ("name" = 'Ivan')`, `SELECT * FROM "people" WHERE ("name" = ?)`},
+		{`SELECT * FROM "people" WHERE ("surname" = 'O''Neil')`, `SELECT * FROM "people" WHERE ("surname" = ?)`},
+		{`SELECT * FROM "people" WHERE ("name" LIKE E'Iv%')`, `SELECT * FROM "people" WHERE ("name" LIKE ?)`},
+		{`SELECT * FROM "people" WHERE (("age" >= 18) AND ("id" > 42)) LIMIT 21`, `SELECT * FROM "people" WHERE (("age" >= ?) AND ("id" > ?)) LIMIT ?`},
+		{`SELECT word_similarity('dmitri', search_key) > 0.3`, `SELECT word_similarity(?, search_key) > ?`},
+		{`UPDATE people SET age = $1 WHERE id = $2`, `UPDATE people SET age = $1 WHERE id = $2`},
+		{`SELECT "name_latin", "v2" FROM "people"`, `SELECT "name_latin", "v2" FROM "people"`},
+		{"SELECT *\n\tFROM people\n", `SELECT * FROM people`},
+	}
+
+	for _, tt := range tests {
+		if got := sanitize(tt.query); got != tt.want {
+			t.Errorf("sanitize(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
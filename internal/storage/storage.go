@@ -1,8 +1,55 @@
-package storage
-
-import "errors"
-
-var (
-	ErrPersonNotFound = errors.New("person not found")
-	ErrPersonExists   = errors.New("person exists")
-)
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrPersonNotFound = errors.New("person not found")
+	ErrPersonExists   = errors.New("person exists")
+	ErrBatchAborted   = errors.New("batch aborted")
+	ErrAffectedDiffer = errors.New("affected rows differ from expected")
+
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	ErrSchemaOutdated = errors.New("database schema is not migrated to the expected version")
+
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key used with another request")
+	ErrIdempotencyKeyInUse    = errors.New("idempotency key in use by a request in progress")
+)
+
+// SaveResult is the outcome of saving one person of a batch.
+type SaveResult struct {
+	Id  int
+	Err error
+}
+
+// ExistsError reports the id of the record that conflicts with a write.
+// It matches ErrPersonExists with errors.Is.
+type ExistsError struct {
+	Id int
+}
+
+func (e *ExistsError) Error() string {
+	return fmt.Sprintf("%s: conflicting id %d", ErrPersonExists, e.Id)
+}
+
+func (e *ExistsError) Unwrap() error {
+	return ErrPersonExists
+}
+
+// BatchConflictError reports that a record conflicts with the one at Index of
+// the same batch, which was rolled back with it. It matches ErrPersonExists
+// with errors.Is.
+type BatchConflictError struct {
+	Index int
+}
+
+func (e *BatchConflictError) Error() string {
+	return fmt.Sprintf("%s: conflicting batch item %d", ErrPersonExists, e.Index)
+}
+
+func (e *BatchConflictError) Unwrap() error {
+	return ErrPersonExists
+}
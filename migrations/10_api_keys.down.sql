@@ -0,0 +1,3 @@
+ALTER TABLE idempotency_keys ALTER COLUMN key TYPE varchar(255) USING left(key, 255);
+
+DROP TABLE IF EXISTS api_keys;
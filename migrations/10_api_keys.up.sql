@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys(
+    id serial PRIMARY KEY,
+    name varchar(255) NOT NULL,
+    key_hash char(64) NOT NULL UNIQUE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    revoked_at timestamptz
+);
+
+-- Idempotency keys are prefixed with the subject of the principal using them.
+ALTER TABLE idempotency_keys ALTER COLUMN key TYPE text;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS people_updated_at_idx;
+DROP INDEX IF EXISTS people_created_at_idx;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS nationality_source,
+    DROP COLUMN IF EXISTS gender_source,
+    DROP COLUMN IF EXISTS age_source,
+    DROP COLUMN IF EXISTS enriched_at,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at;
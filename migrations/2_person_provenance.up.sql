@@ -0,0 +1,10 @@
+ALTER TABLE people
+    ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN enriched_at timestamptz,
+    ADD COLUMN age_source varchar(32) NOT NULL DEFAULT '',
+    ADD COLUMN gender_source varchar(32) NOT NULL DEFAULT '',
+    ADD COLUMN nationality_source varchar(32) NOT NULL DEFAULT '';
+
+CREATE INDEX people_created_at_idx ON "people" USING btree ("created_at");
+CREATE INDEX people_updated_at_idx ON "people" USING btree ("updated_at");
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS people_settings;
+
+DROP INDEX IF EXISTS people_identity_key_un;
+
+ALTER TABLE people DROP COLUMN IF EXISTS identity_key;
+
+CREATE UNIQUE INDEX people_un ON "people" USING btree ("name");
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS people_un;
+
+ALTER TABLE people ADD COLUMN identity_key text;
+
+CREATE UNIQUE INDEX people_identity_key_un ON "people" USING btree ("identity_key");
+
+CREATE TABLE IF NOT EXISTS people_settings(
+    key varchar(64) NOT NULL,
+    value text NOT NULL,
+    PRIMARY KEY(key)
+);
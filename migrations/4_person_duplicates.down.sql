@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS person_aliases;
+
+DROP INDEX IF EXISTS people_search_key_trgm_idx;
+
+ALTER TABLE people DROP COLUMN IF EXISTS search_key;
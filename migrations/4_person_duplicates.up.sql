@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE people ADD COLUMN search_key text NOT NULL DEFAULT '';
+
+CREATE INDEX people_search_key_trgm_idx ON "people" USING gin ("search_key" gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS person_aliases(
+    alias_id integer NOT NULL,
+    person_id integer NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY(alias_id)
+);
+
+CREATE INDEX person_aliases_person_id_idx ON "person_aliases" USING btree ("person_id");
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS people_updated_at_id_idx;
+DROP INDEX IF EXISTS people_created_at_id_idx;
+CREATE INDEX people_created_at_idx ON "people" USING btree ("created_at");
+CREATE INDEX people_updated_at_idx ON "people" USING btree ("updated_at");
+
+DROP INDEX IF EXISTS people_nationality_id_idx;
+DROP INDEX IF EXISTS people_gender_id_idx;
+DROP INDEX IF EXISTS people_age_id_idx;
+DROP INDEX IF EXISTS people_patronymic_id_idx;
+DROP INDEX IF EXISTS people_surname_id_idx;
+DROP INDEX IF EXISTS people_name_id_idx;
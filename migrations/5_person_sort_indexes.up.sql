@@ -0,0 +1,11 @@
+CREATE INDEX people_name_id_idx ON "people" USING btree ("name", "id");
+CREATE INDEX people_surname_id_idx ON "people" USING btree ("surname", "id");
+CREATE INDEX people_patronymic_id_idx ON "people" USING btree ("patronymic", "id");
+CREATE INDEX people_age_id_idx ON "people" USING btree ("age", "id");
+CREATE INDEX people_gender_id_idx ON "people" USING btree ("gender", "id");
+CREATE INDEX people_nationality_id_idx ON "people" USING btree ("nationality", "id");
+
+DROP INDEX IF EXISTS people_created_at_idx;
+DROP INDEX IF EXISTS people_updated_at_idx;
+CREATE INDEX people_created_at_id_idx ON "people" USING btree ("created_at", "id");
+CREATE INDEX people_updated_at_id_idx ON "people" USING btree ("updated_at", "id");
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS people_nationality_id_idx;
+DROP INDEX IF EXISTS people_gender_id_idx;
+DROP INDEX IF EXISTS people_age_id_idx;
+DROP INDEX IF EXISTS people_patronymic_id_idx;
+
+CREATE INDEX people_patronymic_id_idx ON "people" USING btree ("patronymic", "id");
+CREATE INDEX people_age_id_idx ON "people" USING btree ("age", "id");
+CREATE INDEX people_gender_id_idx ON "people" USING btree ("gender", "id");
+CREATE INDEX people_nationality_id_idx ON "people" USING btree ("nationality", "id");
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS people_patronymic_id_idx;
+DROP INDEX IF EXISTS people_age_id_idx;
+DROP INDEX IF EXISTS people_gender_id_idx;
+DROP INDEX IF EXISTS people_nationality_id_idx;
+
+CREATE INDEX people_patronymic_id_idx ON "people" USING btree (coalesce("patronymic", ''), "id");
+CREATE INDEX people_age_id_idx ON "people" USING btree (coalesce("age", 0), "id");
+CREATE INDEX people_gender_id_idx ON "people" USING btree (coalesce("gender", ''), "id");
+CREATE INDEX people_nationality_id_idx ON "people" USING btree (coalesce("nationality", ''), "id");
@@ -0,0 +1,6 @@
+ALTER TABLE people ADD COLUMN search_tsv tsvector
+    GENERATED ALWAYS AS (
+        to_tsvector('simple', name || ' ' || surname || ' ' || coalesce(patronymic, '') || ' ' || search_key)
+    ) STORED;
+
+CREATE INDEX people_search_tsv_idx ON "people" USING gin ("search_tsv");
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS people_surname_latin_idx;
+DROP INDEX IF EXISTS people_name_latin_idx;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS patronymic_latin,
+    DROP COLUMN IF EXISTS surname_latin,
+    DROP COLUMN IF EXISTS name_latin;
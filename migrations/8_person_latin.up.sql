@@ -0,0 +1,7 @@
+ALTER TABLE people
+    ADD COLUMN name_latin varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN surname_latin varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN patronymic_latin varchar(255) NOT NULL DEFAULT '';
+
+CREATE INDEX people_name_latin_idx ON "people" USING btree ("name_latin");
+CREATE INDEX people_surname_latin_idx ON "people" USING btree ("surname_latin");
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys(
+    key varchar(255) NOT NULL,
+    request_hash char(64) NOT NULL,
+    status integer,
+    headers jsonb NOT NULL DEFAULT '{}',
+    body bytea,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    expires_at timestamptz NOT NULL,
+    PRIMARY KEY(key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON "idempotency_keys" USING btree ("expires_at");